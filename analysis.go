@@ -0,0 +1,90 @@
+package vulpes
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+// Analysis is the exact score of one child of an analysed state.
+type Analysis = AnalysisOf[Game]
+
+// AnalysisOf is the generic form of Analysis, for a GameOf[G].
+type AnalysisOf[G any] struct {
+	// Child is a child of the analysed state.
+	Child G
+	// Score is the score of Child, from the perspective of the current player in the analysed state. Unlike the scores a search finds for the children it doesn't choose, it's exact, rather than a bound.
+	Score float64
+	// Depth is the depth the analysed state was searched to, counting the move to Child.
+	Depth uint
+	// PV is the principal variation starting with Child.
+	PV []G
+}
+
+// Analyze returns every child of state, with its exact score at the depth given by the options, best first, as described for SearcherOf.Analyze. It costs more than Solve, which only has to prove that the other children are no better than the best.
+func Analyze(state Game, opts Options) []Analysis {
+	analyses, _ := NewSearcher(opts).Analyze(context.Background(), state)
+	return analyses
+}
+
+// AnalyzeOf is the generic form of Analyze, for a GameOf[G].
+func AnalyzeOf[G GameOf[G]](state G, opts OptionsOf[G]) []AnalysisOf[G] {
+	analyses, _ := NewSearcherOf(opts).Analyze(context.Background(), state)
+	return analyses
+}
+
+// Analyze returns every child of state, with its exact score, best first, keeping the order of Children for equal scores. It returns nil if the game is over.
+// Each child is searched with a full window, so as to score the state to Depth, or to 1 if Depth is 0. If Time or Nodes is set, the children are instead searched one ply deeper at a time until the limits are reached (up to Depth, if it's set), and the deepest completed analysis is returned. The children are searched one at a time, so Threads, Aspiration, Random and the callbacks are ignored, as is Algorithm, other than PVS, which is used within the search of each child.
+// If ctx is done first, the analysis gives up, returning ctx.Err() with the deepest completed analysis, or nil if there's none.
+func (sr *SearcherOf[G]) Analyze(ctx context.Context, state G) ([]AnalysisOf[G], error) {
+	if ending, _ := state.Evaluate(); ending != UNFINISHED {
+		return nil, nil
+	}
+	s := sr.begin(ctx, state)
+	s.onDepth, s.onInfo = nil, nil
+	children := state.Children()
+	var analyses []AnalysisOf[G]
+	if sr.opts.Time <= 0 && sr.opts.Nodes == 0 {
+		depth := sr.opts.Depth
+		if depth == 0 {
+			depth = 1
+		}
+		s.stoppable = s.ctx != nil
+		analyses = s.analyze(children, depth)
+	} else {
+		for depth := uint(1); s.maxDepth == 0 || depth <= s.maxDepth; depth++ {
+			// Always complete the first analysis, so that there's something to return
+			s.stoppable = depth > 1
+			next := s.analyze(children, depth)
+			if s.stopped {
+				break
+			}
+			analyses = next
+			if !s.horizon || (!s.deadline.IsZero() && time.Now().After(s.deadline)) {
+				break
+			}
+		}
+	}
+	if s.stopped && s.ctx != nil && s.ctx.Err() != nil {
+		return analyses, ctx.Err()
+	}
+	return analyses, nil
+}
+
+// analyze searches each of the children of the root with a full window, to score the root to the given depth, returning their analyses sorted best first, or nil if the search is stopped.
+func (s *searcher[G]) analyze(children []G, depth uint) []AnalysisOf[G] {
+	s.depth = depth
+	s.horizon = false
+	analyses := make([]AnalysisOf[G], 0, len(children))
+	for _, child := range children {
+		_, score := s.search(child, depth-1, math.Inf(-1), math.Inf(1), 1)
+		if s.stopped {
+			return nil
+		}
+		pv := s.extendPV(append([]G{child}, s.line(child, 1)...), depth)
+		analyses = append(analyses, AnalysisOf[G]{Child: child, Score: -score, Depth: depth, PV: pv})
+	}
+	sort.SliceStable(analyses, func(i, j int) bool { return analyses[i].Score > analyses[j].Score })
+	return analyses
+}
@@ -0,0 +1,62 @@
+package vulpes
+
+import (
+	"math"
+	"time"
+)
+
+// Aspiration configures the aspiration windows used by iterative deepening. Rather than searching each new depth with an infinite window, it is searched with a narrow window around the score from the previous depth, which prunes far more of the tree whenever the score doesn't move much. If the score falls outside of the window, that side of the window is widened and the depth searched again.
+type Aspiration struct {
+	// Width is the initial distance from the previous score to each edge of the window. If it's 0, aspiration windows aren't used.
+	Width float64
+	// Growth is the factor each side of the window is widened by when the score falls outside of it. Values below 2 are treated as 2.
+	Growth float64
+}
+
+// SolveAspiration searches state to the given depth by iterative deepening, using aspiration windows, and returns the result.
+// If the state implements Hasher, a transposition table of DefaultTableSize entries is used.
+func SolveAspiration(state Game, depth uint, aspiration Aspiration) Result {
+	s := newSearcher[Game](newTable(state))
+	s.maxDepth, s.aspiration = depth, aspiration
+	if depth == 0 {
+		return s.solve(state, depth, math.Inf(-1), math.Inf(1))
+	}
+	return s.deepen(state)
+}
+
+// SolveTimedAspiration is like SolveTimed, but uses aspiration windows.
+func SolveTimedAspiration(state Game, limit time.Duration, aspiration Aspiration) Result {
+	s := newSearcher[Game](newTable(state))
+	s.deadline, s.aspiration = time.Now().Add(limit), aspiration
+	return s.deepen(state)
+}
+
+// aspire searches state to the given depth with aspiration windows around the score from the previous depth.
+func (s *searcher[G]) aspire(state G, depth uint, previous float64) ResultOf[G] {
+	lower, upper := s.aspiration.Width, s.aspiration.Width
+	growth := math.Max(s.aspiration.Growth, 2)
+	for {
+		alpha, beta := math.Inf(-1), math.Inf(1)
+		if s.aspiration.Width > 0 && !isEndingScore(previous) {
+			// Windows near the scores of finished games would confuse wins and losses with heuristics
+			if previous-lower > -WinScore+maxPlies {
+				alpha = previous - lower
+			}
+			if previous+upper < WinScore-maxPlies {
+				beta = previous + upper
+			}
+		}
+		s.horizon = false
+		result := s.solve(state, depth, alpha, beta)
+		switch {
+		case s.stopped:
+			return result
+		case result.Score <= alpha:
+			lower *= growth
+		case result.Score >= beta:
+			upper *= growth
+		default:
+			return result
+		}
+	}
+}
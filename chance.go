@@ -0,0 +1,114 @@
+package vulpes
+
+import (
+	"math"
+)
+
+// Outcome is one of the possible results of a chance event.
+type Outcome = OutcomeOf[Game]
+
+// OutcomeOf is the generic form of Outcome, for a GameOf[G].
+type OutcomeOf[G any] struct {
+	// State is the game state following the event.
+	State G
+	// Probability is the chance of the event having this outcome. The probabilities of the outcomes of an event should sum to 1.
+	Probability float64
+	// SamePlayer is set when the current player of State is the current player of the chance node, rather than their opponent.
+	SamePlayer bool
+}
+
+// ChanceNode is an optional interface a Game may implement for states where the game continues by chance, such as a roll of the dice, rather than by a player's choice. Like any other child, a chance node is scored from the perspective of the opponent of the player moving into it, and Evaluate is still used to detect the end of the game and to order moves.
+// Chance nodes are searched by Expectimax, scoring them as the expected score of their outcomes, with Star1 pruning. They don't count towards the search depth, and aren't stored in the transposition table. They are not supported by SolveMCTS, or as the TacticalChildren of a Tactical game.
+type ChanceNode = ChanceNodeOf[Game]
+
+// ChanceNodeOf is the generic form of ChanceNode, for a GameOf[G].
+type ChanceNodeOf[G any] interface {
+	// Outcomes returns the possible outcomes of the chance event at this state, or nil if the state isn't a chance node.
+	Outcomes() []OutcomeOf[G]
+}
+
+// chance searches a chance node with Star1 pruning: each outcome is searched with a window narrowed by the bounds on the others, such that the whole node fails high (or low) as soon as one outcome does. The bounds start from ±WinScore, which no score can exceed.
+func (s *searcher[G]) chance(outcomes []OutcomeOf[G], depth uint, alpha, beta float64, ply int) float64 {
+	s.chanced = true
+	lo := make([]float64, len(outcomes))
+	hi := make([]float64, len(outcomes))
+	for i := range outcomes {
+		lo[i], hi[i] = -WinScore, WinScore
+	}
+	// rest returns the total of the given bounds over every outcome but the i'th. It's summed afresh each time, as the bounds are far larger than the scores which replace them.
+	rest := func(bounds []float64, i int) float64 {
+		var total float64
+		for j, o := range outcomes {
+			if j != i && o.Probability > 0 {
+				total += o.Probability * bounds[j]
+			}
+		}
+		return total
+	}
+	// window returns the range of scores for the i'th outcome which leave the score of the node within alpha and beta.
+	window := func(i int) (float64, float64) {
+		p := outcomes[i].Probability
+		a, b := (alpha-rest(hi, i))/p, (beta-rest(lo, i))/p
+		if b <= a {
+			// Rounding has closed the window, which must be kept open to tell failing low from failing high
+			b = math.Nextafter(a, math.Inf(1))
+		}
+		return a, b
+	}
+	// ended is set once an outcome is found to be scored as an ended game. Unless the node is settled with every outcome ending the game the same way, its score can't be corrected for the ply like the score of an ended game.
+	var ended bool
+	for i, o := range outcomes {
+		if o.Probability <= 0 {
+			continue
+		}
+		a, b := window(i)
+		score := s.outcomeScore(o, depth, math.Max(a, lo[i]), math.Min(b, hi[i]), ply)
+		if s.stopped {
+			return alpha
+		}
+		ended = ended || isEndingScore(score)
+		if score <= a {
+			s.mixed = s.mixed || ended
+			if s.failSoft {
+				// Rounding may have pushed the bound past alpha, which it's known not to exceed
+				return math.Min(rest(hi, i)+o.Probability*score, alpha)
+			}
+			return alpha
+		}
+		if score >= b {
+			s.mixed = s.mixed || ended
+			if s.failSoft {
+				return math.Max(rest(lo, i)+o.Probability*score, beta)
+			}
+			return beta
+		}
+		lo[i], hi[i] = score, score
+	}
+	var wins, losses, n int
+	for i, o := range outcomes {
+		if o.Probability > 0 {
+			n++
+			if isEndingScore(lo[i]) && lo[i] > 0 {
+				wins++
+			} else if isEndingScore(lo[i]) {
+				losses++
+			}
+		}
+	}
+	s.mixed = s.mixed || (ended && wins != n && losses != n)
+	score := rest(lo, -1)
+	if !s.failSoft {
+		score = math.Min(math.Max(score, alpha), beta)
+	}
+	return score
+}
+
+// outcomeScore searches an outcome of a chance node, returning its score from the perspective of the chance node.
+func (s *searcher[G]) outcomeScore(o OutcomeOf[G], depth uint, alpha, beta float64, ply int) float64 {
+	if o.SamePlayer {
+		_, score := s.search(o.State, depth, alpha, beta, ply+1)
+		return score
+	}
+	_, score := s.search(o.State, depth, -beta, -alpha, ply+1)
+	return -score
+}
@@ -11,6 +11,7 @@ const (
 	rowMask     = filledBoard & (filledBoard >> 7) & (filledBoard >> 14) & (filledBoard >> 21)
 	ldiagMask   = filledBoard & (filledBoard >> 8) & (filledBoard >> 16) & (filledBoard >> 24)
 	rdiagMask   = filledBoard & (filledBoard >> 6) & (filledBoard >> 12) & (filledBoard >> 18)
+	bottomRow   = filledBoard &^ (filledBoard << 1)
 )
 
 type bitboard uint64
@@ -31,6 +32,26 @@ func (b bitboard) isWin() bool {
 	return b&(b>>1)&(b>>2)&(b>>3)|b&(b>>6)&(b>>12)&(b>>18)|b&(b>>7)&(b>>14)&(b>>21)|b&(b>>8)&(b>>16)&(b>>24) != 0
 }
 
+// wins returns the empty spaces which would complete a set of 4 for b.
+func (b bitboard) wins(taken bitboard) bitboard {
+	var w bitboard
+	// Vertical, horizontal, and both diagonals, with the empty space in any of the 4 positions
+	for _, shift := range [4]uint{1, 7, 6, 8} {
+		p := (b << shift) & (b << (2 * shift))
+		w |= p & (b << (3 * shift))
+		w |= p & (b >> shift)
+		p = (b >> shift) & (b >> (2 * shift))
+		w |= p & (b << shift)
+		w |= p & (b >> (3 * shift))
+	}
+	return w & (filledBoard ^ taken)
+}
+
+// playable returns the spaces which can be played next, when b holds the taken spaces.
+func (b bitboard) playable() bitboard {
+	return (b + bottomRow) & filledBoard
+}
+
 func (b bitboard) heur(taken bitboard) int {
 	var heur int
 	ob := taken ^ b
@@ -184,6 +184,21 @@ func (bf bruteForceBitboard) isWin() bool {
 	return f
 }
 
+func (bf bruteForceBitboard) wins(taken bruteForceBitboard) bruteForceBitboard {
+	var r bruteForceBitboard
+	bf.apply4(func(i0, j0, mi, mj int) {
+		if bf.cnt4(i0, j0, mi, mj) != 3 {
+			return
+		}
+		for k := 0; k < 4; k++ {
+			if !taken.index(i0+k*mi, j0+k*mj) {
+				r = r.set(i0+k*mi, j0+k*mj)
+			}
+		}
+	})
+	return r
+}
+
 func (bf bruteForceBitboard) invert() bruteForceBitboard {
 	var r bruteForceBitboard
 	bf.apply(func(i, j int) {
@@ -282,6 +297,18 @@ func TestBitboardWin(t *testing.T) {
 	t.Logf("Number of wins: %v/%v", c, N)
 }
 
+func TestBitboardWins(t *testing.T) {
+	for i := 0; i < N; i++ {
+		bf := randboard()
+		obf := randboard()
+		b := bf.tobitboard()
+		taken := obf.tobitboard() | b
+		if bf.wins(frombitboard(taken)).tobitboard() != b.wins(taken) {
+			t.Errorf("Bitboard wins check failed: b: %v, taken: %v, (%v != %v)", b, taken, bf.wins(frombitboard(taken)), frombitboard(b.wins(taken)))
+		}
+	}
+}
+
 func TestBitboardFilled(t *testing.T) {
 	for i := 0; i < N; i++ {
 		bf := randboard()
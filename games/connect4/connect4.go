@@ -1,12 +1,24 @@
 package connect4
 
 import (
+	"context"
+	"math/bits"
+	"time"
+
 	"github.com/argusdusty/vulpes"
 )
 
+// aspiration suits the heuristic scores, which change in steps of 1, 16 and 256.
+var aspiration = vulpes.Aspiration{Width: 16, Growth: 4}
+
+// passMove is the move ID of a null move.
+const passMove = 7
+
 type connect4 struct {
 	currentPlayer bitboard
 	taken         bitboard
+	// move is the column last played in, or passMove after a null move.
+	move int
 }
 
 func (c connect4) canPlay(col int) bool {
@@ -19,7 +31,7 @@ func (c connect4) play(col int) connect4 {
 	// Since taken columns are of the form 0...01...1, adding 1 to them turns them into 0...10...0, so you can | them to produce a new taken spots with the new move
 	taken := c.taken | (c.taken + (1 << (7 * col)))
 	// Now that taken has been updated, the last player (now currentPlayer ^ taken) has their move set.
-	return connect4{currentPlayer, taken}
+	return connect4{currentPlayer, taken, col}
 }
 
 func (c connect4) Children() []vulpes.Game {
@@ -46,6 +58,52 @@ func (c connect4) Evaluate() (ending int, heuristic float64) {
 	return vulpes.UNFINISHED, float64(c.currentPlayer.heur(c.taken))
 }
 
+// Quiet reports whether neither player can win on their next move.
+func (c connect4) Quiet() bool {
+	playable := c.taken.playable()
+	return (c.currentPlayer.wins(c.taken)|(c.currentPlayer^c.taken).wins(c.taken))&playable == 0
+}
+
+// tactical returns the spaces of the immediate wins for the current player, or failing that, of the moves blocking the next player's immediate wins.
+func (c connect4) tactical() bitboard {
+	playable := c.taken.playable()
+	moves := c.currentPlayer.wins(c.taken) & playable
+	if moves == 0 {
+		moves = (c.currentPlayer ^ c.taken).wins(c.taken) & playable
+	}
+	return moves
+}
+
+// TacticalChildren returns the immediate wins for the current player, or failing that, the moves blocking the next player's immediate wins.
+func (c connect4) TacticalChildren() []vulpes.Game {
+	moves := c.tactical()
+	children := make([]vulpes.Game, 0, bits.OnesCount64(uint64(moves)))
+	for j := 0; j < 7; j++ {
+		if (moves>>(7*j))&0x3f != 0 {
+			children = append(children, c.play(j))
+		}
+	}
+	return children
+}
+
+// NullMove passes the turn to the next player, unless either player can win on their next move, in which case passing would hide the threat.
+func (c connect4) NullMove() (vulpes.Game, bool) {
+	if !c.Quiet() {
+		return nil, false
+	}
+	return connect4{c.currentPlayer ^ c.taken, c.taken, passMove}, true
+}
+
+// MoveID returns the column last played in, or passMove after a null move.
+func (c connect4) MoveID() int {
+	return c.move
+}
+
+// Hash returns a unique key for the position. Adding the bottom row to taken leaves a single marker bit above the top of each column, below which the current player's pieces are kept.
+func (c connect4) Hash() uint64 {
+	return uint64(c.currentPlayer + c.taken + bottomRow)
+}
+
 func (c connect4) String(turn bool) string {
 	out := ""
 	for i := 5; i >= 0; i-- {
@@ -104,10 +162,56 @@ func NewAI(board [6][7]int) *AI {
 
 // MakeMove takes the best move (searching to the given depth) and plays it, updating State. If the game is over, it returns the ending state, and makes no changes to State.
 func (C *AI) MakeMove(depth uint) float64 {
-	best, score := vulpes.SolveGame(C.State, depth)
-	C.State = best.(connect4)
+	result := vulpes.SolveOf(newPosition(C.State), depth)
+	C.State = result.Best.connect4
+	C.Turn = !C.Turn
+	return result.Score
+}
+
+// MakeMoveContext takes the best move (searching to the given depth) and plays it, updating State. If ctx is done before the search completes, it returns ctx.Err(), and makes no changes to State.
+func (C *AI) MakeMoveContext(ctx context.Context, depth uint) (float64, error) {
+	result, err := vulpes.NewSearcherOf(vulpes.OptionsOf[*position]{Depth: depth}).Search(ctx, newPosition(C.State))
+	if err != nil {
+		return result.Score, err
+	}
+	C.State = result.Best.connect4
+	C.Turn = !C.Turn
+	return result.Score, nil
+}
+
+// MakeMoveSearcher takes the best move found by the given searcher and plays it, updating State. If ctx is done before the search completes, it returns ctx.Err(), and makes no changes to State.
+func (C *AI) MakeMoveSearcher(ctx context.Context, searcher *vulpes.Searcher) (float64, error) {
+	result, err := searcher.Search(ctx, C.State)
+	if err != nil {
+		return result.Score, err
+	}
+	C.State = result.Best.(connect4)
 	C.Turn = !C.Turn
-	return score
+	return result.Score, nil
+}
+
+// MakeMoveTimed takes the best move (searching as deep as possible within the given time limit) and plays it, updating State. If the game is over, it returns the ending state, and makes no changes to State.
+func (C *AI) MakeMoveTimed(limit time.Duration) float64 {
+	result, _ := vulpes.NewSearcherOf(vulpes.OptionsOf[*position]{Time: limit, Aspiration: aspiration}).Search(context.Background(), newPosition(C.State))
+	C.State = result.Best.connect4
+	C.Turn = !C.Turn
+	return result.Score
+}
+
+// ColumnScore is the score of playing in a column, from the perspective of the player to move.
+type ColumnScore struct {
+	Column int
+	Score  float64
+}
+
+// Analyze scores every playable column by searching to the given depth, with exact scores for each, best first. It returns nil if the game is over.
+func (C *AI) Analyze(depth uint) []ColumnScore {
+	analyses := vulpes.AnalyzeOf(newPosition(C.State), vulpes.OptionsOf[*position]{Depth: depth})
+	var scores []ColumnScore
+	for _, analysis := range analyses {
+		scores = append(scores, ColumnScore{analysis.Child.move, analysis.Score})
+	}
+	return scores
 }
 
 // String returns a string representation of the game board
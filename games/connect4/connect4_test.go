@@ -1,8 +1,16 @@
 package connect4
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"math/bits"
+	"math/rand"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/argusdusty/vulpes"
 )
 
 func TestAI(t *testing.T) {
@@ -19,6 +27,793 @@ ___X___`
 	}
 }
 
+func TestAITimed(t *testing.T) {
+	c := NewEmptyAI()
+	limit := 500 * time.Millisecond
+	start := time.Now()
+	c.MakeMoveTimed(limit)
+	if elapsed := time.Since(start); elapsed > 2*limit {
+		t.Errorf("Search overran its time limit: %v > %v", elapsed, limit)
+	}
+	if bits.OnesCount64(uint64(c.State.taken)) != 1 {
+		t.Errorf("Bad opening move:\n%s", c.String())
+	}
+}
+
+func TestAIContext(t *testing.T) {
+	c := NewEmptyAI()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.MakeMoveContext(ctx, 42)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Cancelled search returned: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Cancelled search took too long: %v", elapsed)
+	}
+	if c.State != NewEmptyAI().State {
+		t.Errorf("Cancelled search made a move:\n%s", c.String())
+	}
+	best, _, err := vulpes.SolveGameContext(ctx, c.State, 42)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Cancelled search returned: %v", err)
+	}
+	if best == nil {
+		t.Errorf("Cancelled search returned no move")
+	}
+}
+
+// randomState plays up to n random moves from the empty board, stopping early if the game ends.
+func randomState(n int) connect4 {
+	return randomStateWith(rand.Intn, n)
+}
+
+// randomStateWith is like randomState, but chooses the moves with intn, such as the Intn method of a seeded source.
+func randomStateWith(intn func(int) int, n int) connect4 {
+	c := NewEmptyAI().State
+	for i := 0; i < n; i++ {
+		if ending, _ := c.Evaluate(); ending != vulpes.UNFINISHED {
+			break
+		}
+		children := c.Children()
+		c = children[intn(len(children))].(connect4)
+	}
+	return c
+}
+
+func TestHash(t *testing.T) {
+	hashes := map[uint64]connect4{}
+	for i := 0; i < N; i++ {
+		for n := 0; n <= 42; n++ {
+			c := randomState(n)
+			// The last move played doesn't matter to the position
+			if other, ok := hashes[c.Hash()]; ok && (other.currentPlayer != c.currentPlayer || other.taken != c.taken) {
+				t.Errorf("Hash collision: %v, %v", c, other)
+			}
+			hashes[c.Hash()] = c
+		}
+	}
+}
+
+func TestSolveGameTable(t *testing.T) {
+	for i := 0; i < N; i++ {
+		c := randomState(rand.Intn(20))
+		_, score := vulpes.Search(c, 6, math.Inf(-1), math.Inf(1))
+		_, tableScore := vulpes.SolveGame(c, 6)
+		if score != tableScore {
+			t.Errorf("Table changed the score of:\n%v\n%v != %v", c.String(true), tableScore, score)
+		}
+	}
+}
+
+func TestWinDistance(t *testing.T) {
+	// X has three stacked in the first column, and O three in the last, with X to move
+	c := NewEmptyAI().State
+	for _, col := range []int{0, 6, 0, 6, 0, 6} {
+		c = c.play(col)
+	}
+	for depth := uint(1); depth < 8; depth++ {
+		result := vulpes.Solve(c, depth)
+		if ending, plies := vulpes.DecodeScore(result.Score); ending != vulpes.WIN || plies != 1 {
+			t.Errorf("Depth %d: expected a win in 1, got %v in %v", depth, ending, plies)
+		}
+		if result.Best != c.play(0) {
+			t.Errorf("Depth %d: didn't take the immediate win:\n%v", depth, result.Best.(connect4).String(true))
+		}
+	}
+	// O to move, with X threatening both ends of the bottom row
+	c = NewEmptyAI().State
+	for _, col := range []int{1, 1, 2, 2, 3} {
+		c = c.play(col)
+	}
+	result := vulpes.Solve(c, 7)
+	if ending, plies := vulpes.DecodeScore(result.Score); ending != vulpes.LOSS || plies != 2 {
+		t.Errorf("Expected a loss in 2, got %v in %v", ending, plies)
+	}
+}
+
+func TestQuiescence(t *testing.T) {
+	// O has three along the bottom row, with X to move
+	c := NewEmptyAI().State
+	for _, col := range []int{6, 0, 6, 1, 5, 2} {
+		c = c.play(col)
+	}
+	if c.Quiet() {
+		t.Errorf("Pending win is quiet:\n%v", c.String(true))
+	}
+	if children := c.TacticalChildren(); len(children) != 1 || children[0] != c.play(3) {
+		t.Errorf("Bad tactical children: %v", children)
+	}
+	for depth := uint(1); depth < 4; depth++ {
+		result := vulpes.Solve(c, depth)
+		if result.Best != c.play(3) {
+			t.Errorf("Depth %d: didn't block:\n%v", depth, result.Best.(connect4).String(false))
+		}
+		if ending, _ := vulpes.DecodeScore(result.Score); ending != vulpes.UNFINISHED {
+			t.Errorf("Depth %d: unexpected forced ending: %v", depth, ending)
+		}
+	}
+	// Any other move loses
+	result := vulpes.Solve(c.play(6), 0)
+	if ending, plies := vulpes.DecodeScore(result.Score); ending != vulpes.WIN || plies != 1 {
+		t.Errorf("Expected a win in 1, got %v in %v", ending, plies)
+	}
+}
+
+// solveOptions searches state to the given depth with a new Searcher.
+func solveOptions(state vulpes.Game, depth uint, opts vulpes.Options) vulpes.Result {
+	opts.Depth = depth
+	result, _ := vulpes.NewSearcher(opts).Search(context.Background(), state)
+	return result
+}
+
+func TestSearcher(t *testing.T) {
+	for i := 0; i < N; i++ {
+		c := randomState(rand.Intn(30))
+		depth := uint(rand.Intn(8))
+		result := vulpes.Solve(c, depth)
+		for _, algorithm := range []vulpes.Algorithm{vulpes.AlphaBeta, vulpes.PVS, vulpes.MTDf, vulpes.LazySMP} {
+			opts := vulpes.Options{Algorithm: algorithm, Threads: 2}
+			if other := solveOptions(c, depth, opts); other.Score != result.Score {
+				t.Errorf("Algorithm %d changed the score of (depth %d):\n%v\n%v != %v", algorithm, depth, c.String(true), other.Score, result.Score)
+			}
+			opts.Aspiration = aspiration
+			if other := solveOptions(c, depth, opts); depth > 0 && other.Score != result.Score {
+				t.Errorf("Algorithm %d changed the score of (depth %d, deepening):\n%v\n%v != %v", algorithm, depth, c.String(true), other.Score, result.Score)
+			}
+		}
+	}
+	// Deepening stops at the node limit, once a search has completed
+	var depths []uint
+	searcher := vulpes.NewSearcher(vulpes.Options{Nodes: 10000, OnDepth: func(result vulpes.Result) { depths = append(depths, result.Depth) }})
+	result, err := searcher.Search(context.Background(), NewEmptyAI().State)
+	if err != nil || result.Best == nil || result.Depth == 0 {
+		t.Errorf("Bad node limited search: %v, %v", result, err)
+	}
+	if result.Nodes > 10000 {
+		t.Errorf("Search overran its node limit: %d", result.Nodes)
+	}
+	if len(depths) == 0 || depths[len(depths)-1] != result.Depth {
+		t.Errorf("Bad depths reported: %v", depths)
+	}
+	for i := range depths {
+		if depths[i] != uint(i+1) {
+			t.Errorf("Bad depths reported: %v", depths)
+		}
+	}
+	// The stats add up
+	stats := result.Stats
+	if stats.Nodes < result.Nodes || stats.Leaves == 0 || stats.Leaves > stats.Nodes || stats.Elapsed <= 0 || stats.NodesPerSecond() <= 0 {
+		t.Errorf("Bad stats: %+v", stats)
+	}
+	if stats.TotalCutoffs() == 0 || stats.FirstMoveCutoffs > stats.TotalCutoffs() || stats.FirstMoveCutoffRate() > 1 {
+		t.Errorf("Bad cutoff stats: %+v", stats)
+	}
+	if stats.TableProbes == 0 || stats.TableHits > stats.TableProbes || stats.TableCutoffs > stats.TableHits {
+		t.Errorf("Bad table stats: %+v", stats)
+	}
+	if stats := solveOptions(NewEmptyAI().State, 8, vulpes.Options{TableSize: -1}).Stats; stats.TableProbes != 0 {
+		t.Errorf("Probed a missing table: %+v", stats)
+	}
+	// Randomisation chooses between the near-equal opening moves
+	moves := map[connect4]bool{}
+	searcher = vulpes.NewSearcher(vulpes.Options{Depth: 6, Random: 1000, Rand: rand.New(rand.NewSource(1))})
+	for i := 0; i < 20; i++ {
+		result, _ := searcher.Search(context.Background(), NewEmptyAI().State)
+		moves[result.Best.(connect4)] = true
+		if len(result.PV) < 2 || result.PV[0] != result.Best {
+			t.Errorf("Bad randomised PV: %v", result.PV)
+		}
+	}
+	if len(moves) < 2 {
+		t.Errorf("Randomised search always played the same move: %v", moves)
+	}
+	// Randomisation stays within the limits
+	result, _ = vulpes.NewSearcher(vulpes.Options{Nodes: 10000, Random: 1000, Rand: rand.New(rand.NewSource(1))}).Search(context.Background(), NewEmptyAI().State)
+	if result.Nodes > 10000 {
+		t.Errorf("Randomised search overran its node limit: %d", result.Nodes)
+	}
+	limit := 200 * time.Millisecond
+	start := time.Now()
+	result, _ = vulpes.NewSearcher(vulpes.Options{Time: limit, Random: 1000, Rand: rand.New(rand.NewSource(1))}).Search(context.Background(), NewEmptyAI().State)
+	if elapsed := time.Since(start); elapsed > limit+limit/10 || result.Best == nil {
+		t.Errorf("Randomised search overran its time limit: %v > %v", elapsed, limit)
+	}
+}
+
+func TestSearcherOf(t *testing.T) {
+	for i := 0; i < N; i++ {
+		c := randomState(rand.Intn(30))
+		depth := uint(rand.Intn(8))
+		result := vulpes.Solve(c, depth)
+		for _, algorithm := range []vulpes.Algorithm{vulpes.AlphaBeta, vulpes.PVS, vulpes.MTDf, vulpes.LazySMP} {
+			var last vulpes.ResultOf[*position]
+			searcher := vulpes.NewSearcherOf(vulpes.OptionsOf[*position]{Depth: depth, Algorithm: algorithm, Threads: 2, Aspiration: aspiration, OnDepth: func(result vulpes.ResultOf[*position]) { last = result }})
+			generic, err := searcher.Search(context.Background(), newPosition(c))
+			if err != nil || generic.Score != result.Score {
+				t.Errorf("Algorithm %d changed the score of (depth %d, generic):\n%v\n%v != %v, %v", algorithm, depth, c.String(true), generic.Score, result.Score, err)
+			}
+			if last.Best != generic.Best || last.Score != generic.Score {
+				t.Errorf("Bad result reported (depth %d, generic): %v != %v", depth, last, generic)
+			}
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	for i := 0; i < N; i++ {
+		c := randomState(rand.Intn(30))
+		depth := uint(rand.Intn(7)) + 1
+		children := c.Children()
+		analyses := vulpes.Analyze(c, vulpes.Options{Depth: depth, TableSize: -1})
+		if ending, _ := c.Evaluate(); ending != vulpes.UNFINISHED {
+			if analyses != nil {
+				t.Errorf("Analysed an ended game:\n%v\n%v", c.String(true), analyses)
+			}
+			continue
+		}
+		if len(analyses) != len(children) {
+			t.Fatalf("Wrong number of analyses:\n%v\n%d != %d", c.String(true), len(analyses), len(children))
+		}
+		seen := map[connect4]bool{}
+		for j, analysis := range analyses {
+			seen[analysis.Child.(connect4)] = true
+			if j > 0 && analysis.Score > analyses[j-1].Score {
+				t.Errorf("Analyses out of order:\n%v\n%v > %v", c.String(true), analysis.Score, analyses[j-1].Score)
+			}
+			_, score := vulpes.Search(analysis.Child, depth-1, math.Inf(-1), math.Inf(1))
+			if parentScore(score) != analysis.Score {
+				t.Errorf("Wrong score for a child (depth %d):\n%v\n%v != %v", depth, analysis.Child.(connect4).String(false), analysis.Score, parentScore(score))
+			}
+			if len(analysis.PV) == 0 || analysis.PV[0] != analysis.Child || analysis.Depth != depth {
+				t.Errorf("Bad analysis: %+v", analysis)
+			}
+		}
+		if len(seen) != len(children) {
+			t.Errorf("Repeated children in analysis:\n%v\n%v", c.String(true), analyses)
+		}
+		if best := vulpes.Solve(c, depth); best.Score != analyses[0].Score {
+			t.Errorf("Analysis disagrees with Solve (depth %d):\n%v\n%v != %v", depth, c.String(true), analyses[0].Score, best.Score)
+		}
+	}
+	// A UI can show a score for every column
+	scores := NewEmptyAI().Analyze(8)
+	if len(scores) != 7 || scores[0].Column != 3 {
+		t.Errorf("Bad column scores: %v", scores)
+	}
+	// Deepening stops at the limits with a complete analysis
+	searcher := vulpes.NewSearcherOf(vulpes.OptionsOf[*position]{Nodes: 20000})
+	analyses, err := searcher.Analyze(context.Background(), newPosition(NewEmptyAI().State))
+	if err != nil || len(analyses) != 7 || analyses[0].Depth == 0 {
+		t.Errorf("Bad node limited analysis: %v, %v", analyses, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if analyses, err := vulpes.NewSearcher(vulpes.Options{Depth: 12}).Analyze(ctx, NewEmptyAI().State); err != context.Canceled || analyses != nil {
+		t.Errorf("Cancelled analysis returned: %v, %v", analyses, err)
+	}
+}
+
+func TestMultiPV(t *testing.T) {
+	for i := 0; i < N; i++ {
+		c := randomState(rand.Intn(30))
+		depth := uint(rand.Intn(7)) + 1
+		k := rand.Intn(4) + 2
+		analyses := vulpes.Analyze(c, vulpes.Options{Depth: depth, TableSize: -1})
+		for _, algorithm := range []vulpes.Algorithm{vulpes.AlphaBeta, vulpes.PVS} {
+			result := solveOptions(c, depth, vulpes.Options{Algorithm: algorithm, MultiPV: k, TableSize: -1})
+			if analyses == nil {
+				if result.Lines != nil {
+					t.Errorf("Found lines for an ended game:\n%v\n%v", c.String(true), result.Lines)
+				}
+				continue
+			}
+			if len(result.Lines) != k && len(result.Lines) != len(analyses) {
+				t.Fatalf("Wrong number of lines (depth %d, %d lines):\n%v\n%d", depth, k, c.String(true), len(result.Lines))
+			}
+			if result.Best != result.Lines[0].Best || result.Score != result.Lines[0].Score {
+				t.Errorf("Best isn't the first line:\n%v\n%v != %v", c.String(true), result.Score, result.Lines[0].Score)
+			}
+			exact := map[vulpes.Game]float64{}
+			for _, analysis := range analyses {
+				exact[analysis.Child] = analysis.Score
+			}
+			for j, line := range result.Lines {
+				// The scores of the lines are exact, and the best of all the children
+				if line.Score != analyses[j].Score || exact[line.Best] != line.Score {
+					t.Errorf("Wrong score for line %d (depth %d, %d lines):\n%v\n%v != %v", j, depth, k, c.String(true), line.Score, analyses[j].Score)
+				}
+				if len(line.PV) == 0 || line.PV[0] != line.Best {
+					t.Errorf("Bad line: %+v", line)
+				}
+				if j > 0 && line.Best == result.Lines[j-1].Best {
+					t.Errorf("Repeated line: %+v", line)
+				}
+			}
+		}
+	}
+	// The lines are the best of the analysed children
+	c := NewEmptyAI().State
+	searcher := vulpes.NewSearcher(vulpes.Options{Depth: 8, MultiPV: 3, TableSize: -1})
+	result, _ := searcher.Search(context.Background(), c)
+	analysis, _ := vulpes.NewSearcher(vulpes.Options{Depth: 8, TableSize: -1}).Analyze(context.Background(), c)
+	if len(result.Lines) != 3 || result.Lines[0].Score != analysis[0].Score || result.Lines[2].Score != analysis[2].Score {
+		t.Errorf("Bad lines: %v", result.Lines)
+	}
+	// Deepening keeps the lines, whatever the options, and Random doesn't move Best off the first line
+	for _, opts := range []vulpes.Options{
+		{Nodes: 50000, MultiPV: 3},
+		{Depth: 8, MultiPV: 3, Aspiration: aspiration},
+		{Depth: 8, MultiPV: 3, Algorithm: vulpes.MTDf},
+		{Depth: 8, MultiPV: 3, Algorithm: vulpes.LazySMP, Threads: 2},
+		{Depth: 5, MultiPV: 3, Random: 1e6, Rand: rand.New(rand.NewSource(1))},
+	} {
+		result, err := vulpes.NewSearcher(opts).Search(context.Background(), c)
+		if err != nil || len(result.Lines) != 3 || result.Depth == 0 || result.Best != result.Lines[0].Best {
+			t.Errorf("Bad lines with %+v: %v, %v", opts, result.Lines, err)
+		}
+	}
+}
+
+func TestInfo(t *testing.T) {
+	var infos []vulpes.Info
+	opts := vulpes.Options{Depth: 10, Aspiration: aspiration, OnInfo: func(info vulpes.Info) { infos = append(infos, info) }, InfoInterval: time.Nanosecond}
+	result := solveOptions(NewEmptyAI().State, 10, opts)
+	var depth uint
+	var nodes uint64
+	var progress int
+	for _, info := range infos {
+		if info.Nodes < nodes || info.Best == nil || len(info.PV) == 0 || info.PV[0] != info.Best {
+			t.Errorf("Bad info: %+v", info)
+		}
+		nodes = info.Nodes
+		if !info.Completed {
+			progress++
+			if info.Depth != depth+1 {
+				t.Errorf("Progress reported at depth %d, after completing depth %d", info.Depth, depth)
+			}
+			continue
+		}
+		if info.Depth != depth+1 {
+			t.Errorf("Completed depth %d after depth %d", info.Depth, depth)
+		}
+		depth = info.Depth
+	}
+	if depth != 10 || progress == 0 {
+		t.Errorf("Reported up to depth %d, with %d reports in progress", depth, progress)
+	}
+	last := infos[len(infos)-1]
+	if last.Best != result.Best || last.Score != result.Score || !last.Completed {
+		t.Errorf("Last info doesn't match the result: %+v", last)
+	}
+	if s := last.String(); !strings.HasPrefix(s, "depth 10 score ") {
+		t.Errorf("Bad info string: %s", s)
+	}
+	c := NewEmptyAI().State
+	for _, col := range []int{0, 6, 0, 6, 0, 6} {
+		c = c.play(col)
+	}
+	infos = nil
+	solveOptions(c, 3, opts)
+	if s := infos[len(infos)-1].String(); !strings.HasPrefix(s, "depth 3 mate 1 ") {
+		t.Errorf("Bad info string for a win: %s", s)
+	}
+}
+
+func TestSelective(t *testing.T) {
+	// O has three along the bottom row, with X to move, so X mustn't pass
+	c := NewEmptyAI().State
+	for _, col := range []int{6, 0, 6, 1, 5, 2} {
+		c = c.play(col)
+	}
+	if null, ok := c.NullMove(); ok {
+		t.Errorf("Passed with a win pending:\n%v", null.(connect4).String(true))
+	}
+	quiet := NewEmptyAI().State.play(3)
+	null, ok := quiet.NullMove()
+	if !ok || null.(connect4).taken != quiet.taken || null.(connect4).Hash() == quiet.Hash() {
+		t.Errorf("Bad null move: %v", null)
+	}
+	all := vulpes.Options{NullMove: true, LateMoveReductions: true}
+	for depth := uint(1); depth < 8; depth++ {
+		if result := solveOptions(c, depth, all); result.Best != c.play(3) {
+			t.Errorf("Depth %d: didn't block:\n%v", depth, result.Best.(connect4).String(false))
+		}
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < N; i++ {
+		c := randomStateWith(r.Intn, r.Intn(30))
+		depth := uint(r.Intn(8))
+		result := vulpes.Solve(c, depth)
+		if plain := solveOptions(c, depth, vulpes.Options{}); plain.Score != result.Score {
+			t.Errorf("Options changed the score of (depth %d):\n%v\n%v != %v", depth, c.String(true), plain.Score, result.Score)
+		}
+		// Selective searches may miss deeper wins, but never an immediate one, as a state with a win pending isn't quiet, so is never passed
+		selective := solveOptions(c, depth, all)
+		if ending, plies := vulpes.DecodeScore(result.Score); ending == vulpes.WIN && plies == 1 && selective.Score != result.Score {
+			t.Errorf("Selective search missed a win in 1 (depth %d):\n%v\n%v != %v", depth, c.String(true), selective.Score, result.Score)
+		}
+	}
+}
+
+func TestPV(t *testing.T) {
+	for i := 0; i < N; i++ {
+		state := randomState(rand.Intn(20))
+		result := vulpes.Solve(state, 6)
+		if len(result.PV) == 0 {
+			continue
+		}
+		if result.PV[0] != result.Best {
+			t.Errorf("PV doesn't start with the best move: %v != %v", result.PV[0], result.Best)
+		}
+		c := state
+		for _, next := range result.PV {
+			legal := false
+			for _, child := range c.Children() {
+				if child == next {
+					legal = true
+				}
+			}
+			if !legal {
+				t.Fatalf("Illegal move in PV:\n%v\n->\n%v", c.String(true), next.(connect4).String(true))
+			}
+			c = next.(connect4)
+		}
+		ending, heuristic := c.Evaluate()
+		if ending == vulpes.UNFINISHED && (uint(len(result.PV)) < result.Depth || !c.Quiet()) {
+			// Cut short by the transposition table
+			continue
+		}
+		score := heuristic
+		switch ending {
+		case vulpes.LOSS:
+			score = -vulpes.WinScore + float64(len(result.PV))
+		case vulpes.TIE:
+			score = 0
+		}
+		if len(result.PV)%2 == 1 {
+			score = -score
+		}
+		if score != result.Score {
+			t.Errorf("PV doesn't lead to the search score:\n%v\n%v != %v", state.String(true), score, result.Score)
+		}
+	}
+}
+
+func TestPVS(t *testing.T) {
+	for i := 0; i < N; i++ {
+		c := randomState(rand.Intn(30))
+		depth := uint(rand.Intn(8))
+		_, score := vulpes.SolveGame(c, depth)
+		_, pvsScore := vulpes.SolveGamePVS(c, depth)
+		if score != pvsScore {
+			t.Errorf("PVS changed the score of (depth %d):\n%v\n%v != %v", depth, c.String(true), pvsScore, score)
+		}
+		_, score = vulpes.Search(c, depth, math.Inf(-1), math.Inf(1))
+		_, pvsScore = vulpes.SearchPVS(c, depth, math.Inf(-1), math.Inf(1))
+		if score != pvsScore {
+			t.Errorf("PVS changed the score of (depth %d, no table):\n%v\n%v != %v", depth, c.String(true), pvsScore, score)
+		}
+	}
+}
+
+func TestMoveOrdering(t *testing.T) {
+	for i := 0; i < N; i++ {
+		c := randomState(rand.Intn(30))
+		depth := uint(rand.Intn(8))
+		_, score := vulpes.Search(c, depth, math.Inf(-1), math.Inf(1))
+		_, unorderedScore := vulpes.Search(unordered{c}, depth, math.Inf(-1), math.Inf(1))
+		if score != unorderedScore {
+			t.Errorf("Move ordering changed the score of (depth %d):\n%v\n%v != %v", depth, c.String(true), score, unorderedScore)
+		}
+	}
+}
+
+func TestMoveGame(t *testing.T) {
+	for i := 0; i < N; i++ {
+		c := randomState(rand.Intn(30))
+		p := newPosition(c)
+		for _, m := range p.Moves(nil) {
+			p.Play(m)
+			if child := c.play(int(m)); p.connect4 != child {
+				t.Fatalf("Play doesn't match play:\n%v\n%v != %v", c.String(true), p.connect4, child)
+			}
+			p.Undo(m)
+			if p.connect4 != c {
+				t.Fatalf("Undo doesn't restore the state:\n%v\n%v != %v", c.String(true), p.connect4, c)
+			}
+		}
+		depth := uint(rand.Intn(8))
+		result := vulpes.Solve(c, depth)
+		pv := make([]connect4, len(result.PV))
+		for j, next := range result.PV {
+			pv[j] = next.(connect4)
+		}
+		moves := vulpes.Solve(boxed{p}, depth)
+		generic := vulpes.SolveOf(p, depth)
+		for _, search := range []struct {
+			name  string
+			score float64
+			nodes uint64
+			pv    []connect4
+		}{
+			{"Making moves", moves.Score, moves.Nodes, boxedPV(moves.PV)},
+			{"Searching generically", generic.Score, generic.Nodes, positionPV(generic.PV)},
+		} {
+			if search.score != result.Score || search.nodes != result.Nodes {
+				t.Errorf("%s changed the search of (depth %d):\n%v\n%v != %v, %d != %d nodes", search.name, depth, c.String(true), search.score, result.Score, search.nodes, result.Nodes)
+			}
+			if p.connect4 != c {
+				t.Fatalf("%s changed the state:\n%v\n%v", search.name, c.String(true), p.String(true))
+			}
+			if len(search.pv) != len(pv) {
+				t.Errorf("%s changed the PV of (depth %d):\n%v\n%d != %d moves", search.name, depth, c.String(true), len(search.pv), len(pv))
+				continue
+			}
+			for j := range pv {
+				if search.pv[j] != pv[j] {
+					t.Errorf("%s changed the PV of (depth %d):\n%v\n%v != %v", search.name, depth, c.String(true), search.pv[j].String(true), pv[j].String(true))
+					break
+				}
+			}
+		}
+	}
+}
+
+// boxed searches a position through the Game interface, as a vulpes.MoveGame, rather than as a vulpes.GameOf[*position].
+type boxed struct {
+	*position
+}
+
+func box(children []*position) []vulpes.Game {
+	games := make([]vulpes.Game, len(children))
+	for i, child := range children {
+		games[i] = boxed{child}
+	}
+	return games
+}
+
+func (b boxed) Children() []vulpes.Game         { return box(b.position.Children()) }
+func (b boxed) TacticalChildren() []vulpes.Game { return box(b.position.TacticalChildren()) }
+
+func boxedPV(pv []vulpes.Game) []connect4 {
+	states := make([]connect4, len(pv))
+	for i, next := range pv {
+		states[i] = next.(boxed).connect4
+	}
+	return states
+}
+
+func positionPV(pv []*position) []connect4 {
+	states := make([]connect4, len(pv))
+	for i, next := range pv {
+		states[i] = next.connect4
+	}
+	return states
+}
+
+func TestAspiration(t *testing.T) {
+	for i := 0; i < N; i++ {
+		c := randomState(rand.Intn(30))
+		depth := uint(rand.Intn(8))
+		_, score := vulpes.SolveGame(c, depth)
+		for _, width := range []float64{0, 1, 16, 256} {
+			result := vulpes.SolveAspiration(c, depth, vulpes.Aspiration{Width: width, Growth: 4})
+			if result.Score != score {
+				t.Errorf("Aspiration windows of width %v changed the score of (depth %d):\n%v\n%v != %v", width, depth, c.String(true), result.Score, score)
+			}
+		}
+	}
+}
+
+func TestParallel(t *testing.T) {
+	for i := 0; i < N/4; i++ {
+		c := randomState(rand.Intn(30))
+		depth := uint(rand.Intn(8))
+		_, score := vulpes.SolveGame(c, depth)
+		result := vulpes.SolveParallel(c, depth, 4)
+		if result.Score != score {
+			t.Errorf("Parallel search changed the score of (depth %d):\n%v\n%v != %v", depth, c.String(true), result.Score, score)
+		}
+		if ending, _ := c.Evaluate(); ending != vulpes.UNFINISHED || depth == 0 {
+			continue
+		}
+		if result.PV[0] != result.Best {
+			t.Errorf("PV doesn't start with the best move: %v != %v", result.PV[0], result.Best)
+		}
+		if _, childScore := vulpes.SolveGame(result.Best, depth-1); parentScore(childScore) != score {
+			t.Errorf("Parallel search picked a bad move (depth %d):\n%v\n->\n%v\n%v != %v", depth, c.String(true), result.Best.(connect4).String(false), parentScore(childScore), score)
+		}
+	}
+}
+
+func TestLazySMP(t *testing.T) {
+	for i := 0; i < N/4; i++ {
+		c := randomState(rand.Intn(30))
+		depth := uint(rand.Intn(8))
+		result := vulpes.SolveLazySMP(c, depth, 4)
+		if ending, _ := c.Evaluate(); ending != vulpes.UNFINISHED || depth == 0 {
+			continue
+		}
+		legal := false
+		for _, child := range c.Children() {
+			if child == result.Best {
+				legal = true
+			}
+		}
+		if !legal {
+			t.Errorf("Lazy SMP made an illegal move:\n%v\n->\n%v", c.String(true), result.Best.(connect4).String(false))
+		}
+		if result.Depth > depth {
+			t.Errorf("Lazy SMP searched too deep: %d > %d", result.Depth, depth)
+		}
+	}
+	// X has three stacked in the first column, with X to move
+	c := NewEmptyAI().State
+	for _, col := range []int{0, 6, 0, 6, 0, 5} {
+		c = c.play(col)
+	}
+	result := vulpes.SolveLazySMP(c, 8, 4)
+	if ending, plies := vulpes.DecodeScore(result.Score); ending != vulpes.WIN || plies != 1 {
+		t.Errorf("Expected a win in 1, got %v in %v", ending, plies)
+	}
+}
+
+func TestProve(t *testing.T) {
+	// X has three stacked in the first column, and O three in the last, with X to move
+	c := NewEmptyAI().State
+	for _, col := range []int{0, 6, 0, 6, 0, 6} {
+		c = c.play(col)
+	}
+	if proof := vulpes.Prove(c, 0); proof.Outcome != vulpes.WIN || proof.Best != c.play(0) || proof.Size != 2 {
+		t.Errorf("Expected an immediate win, got %v with a proof of %d nodes", proof.Outcome, proof.Size)
+	}
+	// O to move, with X threatening both ends of the bottom row
+	c = NewEmptyAI().State
+	for _, col := range []int{1, 1, 2, 2, 3} {
+		c = c.play(col)
+	}
+	if proof := vulpes.Prove(c, 0); proof.Outcome != vulpes.LOSS {
+		t.Errorf("Expected a loss, got %v", proof.Outcome)
+	}
+	// Midgame positions, checked against a search to the end of the game
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 8; i++ {
+		c = NewEmptyAI().State
+		for moves := 0; moves < 20; moves++ {
+			children := c.Children()
+			c = children[r.Intn(len(children))].(connect4)
+			if ending, _ := c.Evaluate(); ending != vulpes.UNFINISHED || !c.Quiet() {
+				c, moves = NewEmptyAI().State, -1
+			}
+		}
+		proof := vulpes.Prove(c, 1<<20)
+		if proof.Outcome == vulpes.UNFINISHED {
+			t.Logf("Couldn't prove position %d within the node limit", i)
+			continue
+		}
+		want, _ := vulpes.DecodeScore(vulpes.Solve(c, 22).Score)
+		if want == vulpes.UNFINISHED {
+			want = vulpes.TIE
+		}
+		if proof.Outcome != want {
+			t.Errorf("Proved %v, want %v:\n%v", proof.Outcome, want, c.String(true))
+		}
+		t.Logf("Proved position %d a %v with %d nodes, of which %d are in the proof", i, proof.Outcome, proof.Nodes, proof.Size)
+	}
+	if proof := vulpes.Prove(NewEmptyAI().State, 1000); proof.Outcome != vulpes.UNFINISHED {
+		t.Errorf("Proved the empty board %v within 1000 nodes", proof.Outcome)
+	}
+}
+
+func TestTablebase(t *testing.T) {
+	// A late endgame, with 16 plies left to fill the board
+	r := rand.New(rand.NewSource(1))
+	c := NewEmptyAI().State
+	for moves := 0; moves < 26; moves++ {
+		children := c.Children()
+		c = children[r.Intn(len(children))].(connect4)
+		if ending, _ := c.Evaluate(); ending != vulpes.UNFINISHED {
+			c, moves = NewEmptyAI().State, -1
+		}
+	}
+	tb, err := vulpes.GenerateTablebase(c, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("Generated a tablebase of %d positions", tb.Len())
+	want := vulpes.Solve(c, 16).Score
+	if result, _ := vulpes.NewSearcher(vulpes.Options{Depth: 1, Tablebase: tb}).Search(context.Background(), c); result.Score != want {
+		t.Errorf("Tablebase scored %v, want %v:\n%v", result.Score, want, c.String(true))
+	}
+	ending, plies, _ := tb.Probe(c)
+	if wantEnding, wantPlies := vulpes.DecodeScore(want); wantEnding != vulpes.UNFINISHED && (ending != wantEnding || plies != wantPlies) {
+		t.Errorf("Probed %v in %d plies, want %v in %d", ending, plies, wantEnding, wantPlies)
+	}
+}
+
+func TestMCTS(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	// X has three stacked in the first column, and O three in the last, with X to move
+	c := NewEmptyAI().State
+	for _, col := range []int{0, 6, 0, 6, 0, 6} {
+		c = c.play(col)
+	}
+	best, score := vulpes.SolveMCTS(c, vulpes.MCTSOptions{Iterations: 5000, Rand: r})
+	if best != c.play(0) {
+		t.Errorf("Didn't take the win:\n%v", best.(connect4).String(false))
+	}
+	if score != 1 {
+		t.Errorf("Immediate win scored %v", score)
+	}
+	// O to move, and must block
+	c = NewEmptyAI().State
+	for _, col := range []int{0, 6, 0, 6, 0} {
+		c = c.play(col)
+	}
+	best, _ = vulpes.SolveMCTS(c, vulpes.MCTSOptions{Iterations: 5000, Rand: r})
+	if best != c.play(0) {
+		t.Errorf("Didn't block:\n%v", best.(connect4).String(true))
+	}
+}
+
+// parentScore converts the score of a child into the score of its parent, one ply further from the end of the game.
+func parentScore(score float64) float64 {
+	switch ending, _ := vulpes.DecodeScore(-score); ending {
+	case vulpes.WIN:
+		return -score - 1
+	case vulpes.LOSS:
+		return -score + 1
+	}
+	return -score
+}
+
+func TestMTDF(t *testing.T) {
+	for i := 0; i < N; i++ {
+		c := randomState(rand.Intn(30))
+		depth := uint(rand.Intn(8))
+		_, score := vulpes.SolveGame(c, depth)
+		guess := 0.0
+		if depth > 2 {
+			_, guess = vulpes.SolveGame(c, depth-2)
+		}
+		result, passes := vulpes.MTDF(c, depth, guess)
+		if result.Score != score {
+			t.Errorf("MTDF changed the score of (depth %d):\n%v\n%v != %v", depth, c.String(true), result.Score, score)
+		}
+		if passes < 1 {
+			t.Errorf("MTDF took %d passes", passes)
+		}
+		if ending, _ := c.Evaluate(); ending != vulpes.UNFINISHED || depth == 0 {
+			continue
+		}
+		if _, childScore := vulpes.SolveGame(result.Best, depth-1); parentScore(childScore) != score {
+			t.Errorf("MTDF picked a bad move (depth %d):\n%v\n->\n%v\n%v != %v", depth, c.String(true), result.Best.(connect4).String(false), parentScore(childScore), score)
+		}
+	}
+}
+
 func BenchmarkAI(b *testing.B) {
 	for depth := uint(0); depth < 15; depth++ {
 		b.Run(fmt.Sprintf("Depth %d", depth), func(b *testing.B) {
@@ -30,6 +825,61 @@ func BenchmarkAI(b *testing.B) {
 	}
 }
 
+func BenchmarkAIPVS(b *testing.B) {
+	for depth := uint(0); depth < 15; depth++ {
+		b.Run(fmt.Sprintf("Depth %d", depth), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				vulpes.SolveGamePVS(NewEmptyAI().State, depth)
+			}
+		})
+	}
+}
+
+func BenchmarkAIMTDF(b *testing.B) {
+	for depth := uint(0); depth < 15; depth++ {
+		b.Run(fmt.Sprintf("Depth %d", depth), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				vulpes.MTDF(NewEmptyAI().State, depth, 0)
+			}
+		})
+	}
+}
+
+func BenchmarkAIAspiration(b *testing.B) {
+	for depth := uint(0); depth < 15; depth++ {
+		b.Run(fmt.Sprintf("Depth %d", depth), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				vulpes.SolveAspiration(NewEmptyAI().State, depth, aspiration)
+			}
+		})
+	}
+}
+
+func BenchmarkAIParallel(b *testing.B) {
+	for depth := uint(0); depth < 15; depth++ {
+		b.Run(fmt.Sprintf("Depth %d", depth), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				vulpes.SolveParallel(NewEmptyAI().State, depth, 0)
+			}
+		})
+	}
+}
+
+func BenchmarkAILazySMP(b *testing.B) {
+	for _, threads := range []int{1, 2, 4, 8} {
+		for depth := uint(0); depth < 15; depth++ {
+			b.Run(fmt.Sprintf("Threads %d/Depth %d", threads, depth), func(b *testing.B) {
+				var nodes uint64
+				start := time.Now()
+				for i := 0; i < b.N; i++ {
+					nodes += vulpes.SolveLazySMP(NewEmptyAI().State, depth, threads).Nodes
+				}
+				b.ReportMetric(float64(nodes)/time.Since(start).Seconds(), "nodes/s")
+			})
+		}
+	}
+}
+
 /*
 func BenchmarkAISolve(b *testing.B) {
 	for i := 0; i < b.N; i++ {
@@ -38,3 +888,98 @@ func BenchmarkAISolve(b *testing.B) {
 	}
 }
 */
+
+// unordered hides the MoveID of a connect4 state, so that the search can't use the killer and history heuristics to order its children.
+type unordered struct {
+	c connect4
+}
+
+func hideMoves(children []vulpes.Game) []vulpes.Game {
+	for i, child := range children {
+		children[i] = unordered{child.(connect4)}
+	}
+	return children
+}
+
+func (u unordered) Children() []vulpes.Game         { return hideMoves(u.c.Children()) }
+func (u unordered) Evaluate() (int, float64)        { return u.c.Evaluate() }
+func (u unordered) Quiet() bool                     { return u.c.Quiet() }
+func (u unordered) TacticalChildren() []vulpes.Game { return hideMoves(u.c.TacticalChildren()) }
+func (u unordered) Hash() uint64                    { return u.c.Hash() }
+
+func BenchmarkAIMoveOrdering(b *testing.B) {
+	for _, ordering := range []struct {
+		name  string
+		state vulpes.Game
+	}{{"Heuristic", unordered{NewEmptyAI().State}}, {"Killers", NewEmptyAI().State}} {
+		for depth := uint(0); depth < 15; depth++ {
+			b.Run(fmt.Sprintf("%s/Depth %d", ordering.name, depth), func(b *testing.B) {
+				var stats vulpes.Stats
+				for i := 0; i < b.N; i++ {
+					stats = vulpes.Solve(ordering.state, depth).Stats
+				}
+				b.ReportMetric(float64(stats.Nodes), "nodes/op")
+				b.ReportMetric(100*stats.FirstMoveCutoffRate(), "%first-cutoffs")
+			})
+		}
+	}
+}
+
+func BenchmarkAIMoves(b *testing.B) {
+	for _, traversal := range []struct {
+		name  string
+		solve func(depth uint) uint64
+	}{
+		{"Children", func(depth uint) uint64 { return vulpes.Solve(NewEmptyAI().State, depth).Nodes }},
+		{"Moves", func(depth uint) uint64 { return vulpes.Solve(boxed{newPosition(NewEmptyAI().State)}, depth).Nodes }},
+		{"Generic", func(depth uint) uint64 { return vulpes.SolveOf(newPosition(NewEmptyAI().State), depth).Nodes }},
+	} {
+		for depth := uint(8); depth < 13; depth++ {
+			b.Run(fmt.Sprintf("%s/Depth %d", traversal.name, depth), func(b *testing.B) {
+				b.ReportAllocs()
+				var nodes uint64
+				start := time.Now()
+				for i := 0; i < b.N; i++ {
+					nodes += traversal.solve(depth)
+				}
+				b.ReportMetric(float64(nodes)/time.Since(start).Seconds(), "nodes/s")
+			})
+		}
+	}
+}
+
+func BenchmarkAIMultiPV(b *testing.B) {
+	for _, k := range []int{1, 3, 7} {
+		for depth := uint(6); depth < 13; depth += 2 {
+			b.Run(fmt.Sprintf("%d lines/Depth %d", k, depth), func(b *testing.B) {
+				var nodes uint64
+				for i := 0; i < b.N; i++ {
+					nodes += solveOptions(NewEmptyAI().State, depth, vulpes.Options{MultiPV: k}).Nodes
+				}
+				b.ReportMetric(float64(nodes)/float64(b.N), "nodes/op")
+			})
+		}
+	}
+}
+
+func BenchmarkAISelective(b *testing.B) {
+	for _, selective := range []struct {
+		name string
+		opts vulpes.Options
+	}{
+		{"None", vulpes.Options{}},
+		{"NullMove", vulpes.Options{NullMove: true}},
+		{"LMR", vulpes.Options{LateMoveReductions: true}},
+		{"Both", vulpes.Options{NullMove: true, LateMoveReductions: true}},
+	} {
+		for depth := uint(0); depth < 15; depth++ {
+			b.Run(fmt.Sprintf("%s/Depth %d", selective.name, depth), func(b *testing.B) {
+				var nodes uint64
+				for i := 0; i < b.N; i++ {
+					nodes += solveOptions(NewEmptyAI().State, depth, selective.opts).Nodes
+				}
+				b.ReportMetric(float64(nodes)/float64(b.N), "nodes/op")
+			})
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package connect4
+
+import (
+	"math/bits"
+
+	"github.com/argusdusty/vulpes"
+)
+
+// position is a connect4 state which implements vulpes.GameOf[*position], so that it's searched without boxing each state in an interface, and vulpes.TacticalMoverOf[*position], so that it's searched by playing and undoing moves in place, rather than by allocating its children.
+type position struct {
+	connect4
+	// previous holds the move of the state before each move played, so that Undo can restore it.
+	previous [42]int8
+	played   int
+}
+
+// newPosition returns a position at the given state, with no moves to undo.
+func newPosition(c connect4) *position {
+	return &position{connect4: c}
+}
+
+func (p *position) Children() []*position {
+	children := make([]*position, 0, 7)
+	for j := 0; j < 7; j++ {
+		if p.canPlay(j) {
+			children = append(children, newPosition(p.play(j)))
+		}
+	}
+	return children
+}
+
+func (p *position) TacticalChildren() []*position {
+	moves := p.tactical()
+	children := make([]*position, 0, bits.OnesCount64(uint64(moves)))
+	for j := 0; j < 7; j++ {
+		if (moves>>(7*j))&0x3f != 0 {
+			children = append(children, newPosition(p.play(j)))
+		}
+	}
+	return children
+}
+
+// NullMove passes the turn to the next player, as connect4's NullMove does.
+func (p *position) NullMove() (*position, bool) {
+	if !p.Quiet() {
+		return nil, false
+	}
+	return newPosition(connect4{p.currentPlayer ^ p.taken, p.taken, passMove}), true
+}
+
+// Moves appends the playable columns, in the same order as Children.
+func (p *position) Moves(buf []vulpes.Move) []vulpes.Move {
+	for j := 0; j < 7; j++ {
+		if p.canPlay(j) {
+			buf = append(buf, vulpes.Move(j))
+		}
+	}
+	return buf
+}
+
+// TacticalMoves appends the columns of the tactical children, in the same order as TacticalChildren.
+func (p *position) TacticalMoves(buf []vulpes.Move) []vulpes.Move {
+	moves := p.tactical()
+	for j := 0; j < 7; j++ {
+		if (moves>>(7*j))&0x3f != 0 {
+			buf = append(buf, vulpes.Move(j))
+		}
+	}
+	return buf
+}
+
+// Play drops a piece in the column.
+func (p *position) Play(m vulpes.Move) {
+	p.previous[p.played] = int8(p.move)
+	p.played++
+	p.connect4 = p.play(int(m))
+}
+
+// Undo takes the top piece back out of the column.
+func (p *position) Undo(m vulpes.Move) {
+	col := uint(m)
+	// The taken spaces of a column are of the form 0...01...1, so adding 1 and halving leaves just the top one
+	column := (p.taken >> (7 * col)) & 0x3f
+	p.taken ^= ((column + 1) >> 1) << (7 * col)
+	// play set currentPlayer to the previous player's pieces, which are the other taken spaces
+	p.currentPlayer ^= p.taken
+	p.played--
+	p.move = int(p.previous[p.played])
+}
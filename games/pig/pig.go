@@ -0,0 +1,121 @@
+package pig
+
+import (
+	"fmt"
+
+	"github.com/argusdusty/vulpes"
+)
+
+// pig is a state of a game of Pig, in which players take turns to roll a die as many times as they like, adding the rolls to their turn total, until they either hold, banking the turn total, or roll a 1, losing it. The first player to bank the goal wins.
+type pig struct {
+	goal int
+	// player and opponent are the banked scores of the player whose turn it is, and of their opponent.
+	player, opponent int
+	turn             int
+	// rolling is set once the player has chosen to roll, making this a chance node. It's then scored from the perspective of the opponent, as with any other move.
+	rolling bool
+}
+
+// roll returns the state after the current player rolls the given die, and whether it's still their turn.
+func (p pig) roll(die int) (pig, bool) {
+	if die == 1 {
+		return pig{p.goal, p.opponent, p.player, 0, false}, false
+	}
+	return pig{p.goal, p.player, p.opponent, p.turn + die, false}, true
+}
+
+func (p pig) Children() []vulpes.Game {
+	roll := pig{p.goal, p.player, p.opponent, p.turn, true}
+	if p.turn == 0 {
+		// A player must roll at least once each turn
+		return []vulpes.Game{roll}
+	}
+	return []vulpes.Game{roll, pig{p.goal, p.opponent, p.player + p.turn, 0, false}}
+}
+
+// Outcomes returns the results of each roll of the die, if the current player has chosen to roll.
+func (p pig) Outcomes() []vulpes.Outcome {
+	if !p.rolling {
+		return nil
+	}
+	outcomes := make([]vulpes.Outcome, 6)
+	for die := 1; die <= 6; die++ {
+		state, sameTurn := p.roll(die)
+		// The chance node is scored for the roller's opponent, so it's only their turn again if the roll is a 1
+		outcomes[die-1] = vulpes.Outcome{State: state, Probability: 1.0 / 6, SamePlayer: !sameTurn}
+	}
+	return outcomes
+}
+
+// Evaluate scores the game by the lead of the current player, counting their turn total.
+func (p pig) Evaluate() (ending int, heuristic float64) {
+	if p.opponent >= p.goal {
+		return vulpes.LOSS, 0
+	}
+	lead := float64(p.player + p.turn - p.opponent)
+	if p.rolling {
+		return vulpes.UNFINISHED, -lead
+	}
+	return vulpes.UNFINISHED, lead
+}
+
+// Hash returns a unique key for the position, among games to the same goal below 2^16.
+func (p pig) Hash() uint64 {
+	hash := uint64(p.player)<<33 | uint64(p.opponent)<<17 | uint64(p.turn)<<1
+	if p.rolling {
+		hash |= 1
+	}
+	return hash
+}
+
+func (p pig) String() string {
+	s := fmt.Sprintf("Player: %d, Opponent: %d, Turn: %d", p.player, p.opponent, p.turn)
+	if p.rolling {
+		s += " (rolling)"
+	}
+	return s
+}
+
+// AI uses vulpes to play Pig
+type AI struct {
+	State pig
+	// Turn is the player whose turn it is, alternating between 0 and 1
+	Turn int
+}
+
+// NewAI returns a Pig AI for a game to the given goal
+func NewAI(goal int) *AI {
+	return &AI{State: pig{goal: goal}}
+}
+
+// MakeMove decides (searching to the given depth) whether to roll or hold, updating State, and returns the score of the decision. If it rolls, Roll must be called with the result before the next move.
+func (C *AI) MakeMove(depth uint) float64 {
+	best, score := vulpes.SolveGame(C.State, depth)
+	C.State = best.(pig)
+	if !C.State.rolling {
+		C.Turn = 1 - C.Turn
+	}
+	return score
+}
+
+// Rolling reports whether the current player has chosen to roll, and is waiting for the result.
+func (C *AI) Rolling() bool {
+	return C.State.rolling
+}
+
+// Roll updates State with the result of rolling the given die, from 1 to 6.
+func (C *AI) Roll(die int) {
+	if !C.State.rolling || die < 1 || die > 6 {
+		panic("Invalid roll")
+	}
+	state, sameTurn := C.State.roll(die)
+	C.State = state
+	if !sameTurn {
+		C.Turn = 1 - C.Turn
+	}
+}
+
+// String returns a string representation of the game state
+func (C *AI) String() string {
+	return C.State.String()
+}
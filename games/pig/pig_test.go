@@ -0,0 +1,127 @@
+package pig
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/argusdusty/vulpes"
+)
+
+// expectimax scores a state by a plain search of the whole tree to the given depth.
+func expectimax(state vulpes.Game, depth uint, ply int) float64 {
+	ending, heuristic := state.Evaluate()
+	switch ending {
+	case vulpes.WIN:
+		return vulpes.WinScore - float64(ply)
+	case vulpes.LOSS:
+		return -vulpes.WinScore + float64(ply)
+	case vulpes.TIE:
+		return 0
+	}
+	if outcomes := state.(vulpes.ChanceNode).Outcomes(); outcomes != nil {
+		var score float64
+		for _, o := range outcomes {
+			v := expectimax(o.State, depth, ply+1)
+			if !o.SamePlayer {
+				v = -v
+			}
+			score += o.Probability * v
+		}
+		return score
+	}
+	if depth == 0 {
+		return heuristic
+	}
+	score := math.Inf(-1)
+	for _, child := range state.Children() {
+		score = math.Max(score, -expectimax(child, depth-1, ply+1))
+	}
+	return score
+}
+
+// testStates returns a selection of positions in a game to 12.
+func testStates() []pig {
+	var states []pig
+	for player := 0; player < 12; player += 3 {
+		for opponent := 0; opponent < 12; opponent += 4 {
+			for turn := 0; player+turn < 14; turn += 5 {
+				states = append(states, pig{12, player, opponent, turn, false}, pig{12, player, opponent, turn, true})
+			}
+		}
+	}
+	return states
+}
+
+func closeScores(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
+
+func TestExpectimax(t *testing.T) {
+	for _, state := range testStates() {
+		for depth := uint(0); depth <= 4; depth++ {
+			want := expectimax(state, depth, 0)
+			if _, score := vulpes.Search(state, depth, math.Inf(-1), math.Inf(1)); !closeScores(score, want) {
+				t.Errorf("Search(%v, %d) = %v, want %v", state, depth, score, want)
+			}
+			if _, score := vulpes.SearchPVS(state, depth, math.Inf(-1), math.Inf(1)); !closeScores(score, want) {
+				t.Errorf("SearchPVS(%v, %d) = %v, want %v", state, depth, score, want)
+			}
+			if result := vulpes.Solve(state, depth); !closeScores(result.Score, want) {
+				t.Errorf("Solve(%v, %d) = %v, want %v", state, depth, result.Score, want)
+			}
+			if result, _ := vulpes.MTDF(state, depth, 0); !closeScores(result.Score, want) {
+				t.Errorf("MTDF(%v, %d) = %v, want %v", state, depth, result.Score, want)
+			}
+			// A window around the score must contain it
+			if _, score := vulpes.Search(state, depth, want-1, want+1); !closeScores(score, want) {
+				t.Errorf("Search(%v, %d) in a narrow window = %v, want %v", state, depth, score, want)
+			}
+		}
+	}
+}
+
+func TestHold(t *testing.T) {
+	// Holding wins the game
+	c := &AI{State: pig{20, 15, 18, 6, false}}
+	c.MakeMove(3)
+	if c.Rolling() || c.State != (pig{20, 18, 21, 0, false}) {
+		t.Errorf("Didn't hold to win: %v", c)
+	}
+	if c.Turn != 1 {
+		t.Errorf("Turn didn't pass after holding: %d", c.Turn)
+	}
+	// The opponent is about to win, so it's worth rolling
+	c = &AI{State: pig{20, 0, 19, 6, false}}
+	c.MakeMove(3)
+	if !c.Rolling() {
+		t.Errorf("Didn't roll when behind: %v", c)
+	}
+	c.Roll(1)
+	if c.State != (pig{20, 19, 0, 0, false}) || c.Turn != 1 {
+		t.Errorf("Rolling a 1 didn't lose the turn: %v", c)
+	}
+}
+
+func TestGame(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	c := NewAI(30)
+	for {
+		if ending, _ := c.State.Evaluate(); ending != vulpes.UNFINISHED {
+			break
+		}
+		c.MakeMove(4)
+		if c.Rolling() {
+			c.Roll(r.Intn(6) + 1)
+		}
+	}
+	if c.State.opponent < 30 {
+		t.Errorf("Game ended early: %v", c)
+	}
+}
+
+func BenchmarkAI(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		vulpes.SolveGame(pig{100, 0, 0, 10, false}, 6)
+	}
+}
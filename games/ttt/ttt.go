@@ -1,6 +1,9 @@
 package ttt
 
 import (
+	"context"
+	"time"
+
 	"github.com/argusdusty/vulpes"
 )
 
@@ -84,6 +87,19 @@ func (t ttt) Evaluate() (ending int, heuristic float64) {
 	return tieEval(a, b, c, d, e, f, g, h, i), 0
 }
 
+// Hash returns a unique key for the position, treating the board as a base-3 number.
+func (t ttt) Hash() uint64 {
+	var hash uint64
+	for i := 0; i < 9; i++ {
+		hash = 3*hash + uint64(t.board[i]+1)
+	}
+	hash <<= 1
+	if t.turn {
+		hash |= 1
+	}
+	return hash
+}
+
 func (t ttt) String() string {
 	out := ""
 	for i := 0; i < 3; i++ {
@@ -139,6 +155,36 @@ func (C *AI) MakeMove(depth uint) float64 {
 	return score
 }
 
+// MakeMoveContext takes the best move (searching to the given depth) and plays it, updating State. If ctx is done before the search completes, it returns ctx.Err(), and makes no changes to State.
+func (C *AI) MakeMoveContext(ctx context.Context, depth uint) (float64, error) {
+	best, score, err := vulpes.SolveGameContext(ctx, C.State, depth)
+	if err != nil {
+		return score, err
+	}
+	C.State = best.(ttt)
+	C.Turn = !C.Turn
+	return score, nil
+}
+
+// MakeMoveSearcher takes the best move found by the given searcher and plays it, updating State. If ctx is done before the search completes, it returns ctx.Err(), and makes no changes to State.
+func (C *AI) MakeMoveSearcher(ctx context.Context, searcher *vulpes.Searcher) (float64, error) {
+	result, err := searcher.Search(ctx, C.State)
+	if err != nil {
+		return result.Score, err
+	}
+	C.State = result.Best.(ttt)
+	C.Turn = !C.Turn
+	return result.Score, nil
+}
+
+// MakeMoveTimed takes the best move (searching as deep as possible within the given time limit) and plays it, updating State. If the game is over, it returns the ending state, and makes no changes to State.
+func (C *AI) MakeMoveTimed(limit time.Duration) float64 {
+	best, score := vulpes.SolveGameTimed(C.State, limit)
+	C.State = best.(ttt)
+	C.Turn = !C.Turn
+	return score
+}
+
 // String returns a string representation of the game board
 func (C *AI) String() string {
 	return C.State.String()
@@ -1,8 +1,13 @@
 package ttt
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"math"
+	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/argusdusty/vulpes"
 )
@@ -78,6 +83,331 @@ ___`
 	}
 }
 
+func TestAITimed(t *testing.T) {
+	c := NewEmptyAI()
+	start := time.Now()
+	score := c.MakeMoveTimed(time.Minute)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Solved game took too long: %v", elapsed)
+	}
+	target := `X__
+___
+___`
+	if c.String() != target {
+		t.Errorf("Bad opening X move: %s != %s", c.String(), target)
+	}
+	if score != 0 {
+		t.Errorf("Non-zero TTT score: %v", score)
+	}
+}
+
+func TestAIContext(t *testing.T) {
+	c := NewEmptyAI()
+	score, err := c.MakeMoveContext(context.Background(), 9)
+	if err != nil {
+		t.Errorf("Uncancelled search failed: %v", err)
+	}
+	target := `X__
+___
+___`
+	if c.String() != target {
+		t.Errorf("Bad opening X move: %s != %s", c.String(), target)
+	}
+	if score != 0 {
+		t.Errorf("Non-zero TTT score: %v", score)
+	}
+}
+
+func TestAISearcher(t *testing.T) {
+	c := NewEmptyAI()
+	// The searcher's table carries over from one move to the next
+	searcher := vulpes.NewSearcher(vulpes.Options{Depth: 9, Algorithm: vulpes.PVS})
+	for _, target := range []string{"X__\n___\n___", "X__\n_O_\n___"} {
+		score, err := c.MakeMoveSearcher(context.Background(), searcher)
+		if err != nil {
+			t.Errorf("Uncancelled search failed: %v", err)
+		}
+		if c.String() != target {
+			t.Errorf("Bad move: %s != %s", c.String(), target)
+		}
+		if score != 0 {
+			t.Errorf("Non-zero TTT score: %v", score)
+		}
+	}
+}
+
+func TestPV(t *testing.T) {
+	state := NewEmptyAI().State
+	result := vulpes.Solve(state, 9)
+	if len(result.PV) != 9 {
+		t.Fatalf("Expected a 9 move PV, got %d", len(result.PV))
+	}
+	if result.PV[0] != result.Best {
+		t.Errorf("PV doesn't start with the best move: %v != %v", result.PV[0], result.Best)
+	}
+	for _, next := range result.PV {
+		legal := false
+		for _, child := range state.Children() {
+			if child == next {
+				legal = true
+			}
+		}
+		if !legal {
+			t.Fatalf("Illegal move in PV:\n%v\n->\n%v", state, next)
+		}
+		state = next.(ttt)
+	}
+	if ending, _ := state.Evaluate(); ending != vulpes.TIE {
+		t.Errorf("PV doesn't end in a tie:\n%v", state)
+	}
+}
+
+func TestWinDistance(t *testing.T) {
+	// X to move, and can win immediately
+	c := NewAI([9]int{1, 1, 0, -1, -1, 0, 0, 0, 0})
+	_, score := vulpes.SolveGame(c.State, 9)
+	if ending, plies := vulpes.DecodeScore(score); ending != vulpes.WIN || plies != 1 {
+		t.Errorf("Expected a win in 1, got %v in %v", ending, plies)
+	}
+	// O to move, and X has three threats
+	c = NewAI([9]int{1, 1, 0, -1, 1, 0, -1, 0, 0})
+	_, score = vulpes.SolveGame(c.State, 9)
+	if ending, plies := vulpes.DecodeScore(score); ending != vulpes.LOSS || plies != 2 {
+		t.Errorf("Expected a loss in 2, got %v in %v", ending, plies)
+	}
+	// The empty board is a tie
+	_, score = vulpes.SolveGame(NewEmptyAI().State, 9)
+	if ending, _ := vulpes.DecodeScore(score); ending != vulpes.UNFINISHED {
+		t.Errorf("Expected no forced ending, got %v", ending)
+	}
+}
+
+func reachable(t ttt, seen map[ttt]bool) {
+	if seen[t] {
+		return
+	}
+	seen[t] = true
+	if ending, _ := t.Evaluate(); ending != vulpes.UNFINISHED {
+		return
+	}
+	for _, child := range t.Children() {
+		reachable(child.(ttt), seen)
+	}
+}
+
+// outcome returns the ending of a game with perfect play, by searching to the end.
+func outcome(state ttt) int {
+	_, score := vulpes.Search(state, 9, math.Inf(-1), math.Inf(1))
+	if ending, _ := vulpes.DecodeScore(score); ending != vulpes.UNFINISHED {
+		return ending
+	}
+	return vulpes.TIE
+}
+
+func TestProve(t *testing.T) {
+	seen := map[ttt]bool{}
+	reachable(NewEmptyAI().State, seen)
+	for state := range seen {
+		want := outcome(state)
+		proof := vulpes.Prove(state, 0)
+		if proof.Outcome != want {
+			t.Fatalf("Proved %v, want %v:\n%v", proof.Outcome, want, state)
+		}
+		if ending, _ := state.Evaluate(); ending != vulpes.UNFINISHED {
+			continue
+		}
+		// The best move must leave the opponent with the opposite outcome
+		if got := outcome(proof.Best.(ttt)); got != 2-want {
+			t.Errorf("Best move leaves the opponent with %v, want %v:\n%v", got, 2-want, proof.Best)
+		}
+	}
+	proof := vulpes.Prove(NewEmptyAI().State, 0)
+	t.Logf("Proved the empty board a tie with %d nodes, of which %d are in the proof", proof.Nodes, proof.Size)
+	if proof := vulpes.Prove(NewEmptyAI().State, 100); proof.Outcome != vulpes.UNFINISHED {
+		t.Errorf("Proved %v within 100 nodes", proof.Outcome)
+	}
+}
+
+func TestTablebase(t *testing.T) {
+	seen := map[ttt]bool{}
+	reachable(NewEmptyAI().State, seen)
+	tb, err := vulpes.GenerateTablebase(NewEmptyAI().State, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tb.Len() != len(seen) {
+		t.Errorf("Tablebase holds %d positions, want %d", tb.Len(), len(seen))
+	}
+	var buf bytes.Buffer
+	if _, err := tb.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("Tablebase of %d positions takes %d bytes", tb.Len(), buf.Len())
+	tb, err = vulpes.ReadTablebase(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for state := range seen {
+		ending, plies, ok := tb.Probe(state)
+		if !ok {
+			t.Fatalf("Missing position:\n%v", state)
+		}
+		_, score := vulpes.Search(state, 9, math.Inf(-1), math.Inf(1))
+		wantEnding, wantPlies := vulpes.DecodeScore(score)
+		if wantEnding == vulpes.UNFINISHED {
+			// Ties are all scored 0, so the plies can't be checked
+			wantEnding, wantPlies = vulpes.TIE, plies
+		}
+		if ending != wantEnding || plies != wantPlies {
+			t.Errorf("Probed %v in %d plies, want %v in %d:\n%v", ending, plies, wantEnding, wantPlies, state)
+		}
+	}
+	if _, _, ok := tb.Probe(ttt{[9]int{1, 1, 1, 1, 1, 0, 0, 0, 0}, false}); ok {
+		t.Errorf("Found an unreachable position")
+	}
+	// Searching to depth 1 finds the exact score of every child
+	c := NewAI([9]int{1, 1, 0, -1, 1, 0, -1, 0, 0})
+	result, _ := vulpes.NewSearcher(vulpes.Options{Depth: 1, Tablebase: tb}).Search(context.Background(), c.State)
+	if ending, plies := vulpes.DecodeScore(result.Score); ending != vulpes.LOSS || plies != 2 {
+		t.Errorf("Expected a loss in 2, got %v in %v", ending, plies)
+	}
+	// So deepening stops there, whatever the algorithm
+	for _, algorithm := range []vulpes.Algorithm{vulpes.AlphaBeta, vulpes.PVS, vulpes.MTDf, vulpes.LazySMP} {
+		deepened, err := vulpes.NewSearcher(vulpes.Options{Time: time.Minute, Algorithm: algorithm, Threads: 2, Tablebase: tb}).Search(context.Background(), c.State)
+		if err != nil || deepened.Depth != 1 || deepened.Score != result.Score {
+			t.Errorf("Algorithm %d deepened to %d, scoring %v: %v", algorithm, deepened.Depth, deepened.Score, err)
+		}
+	}
+	if _, err := vulpes.GenerateTablebase(NewEmptyAI().State, 1000); err != vulpes.ErrTablebaseTooLarge {
+		t.Errorf("Generating a tablebase over the limit gave %v", err)
+	}
+	if _, err := vulpes.ReadTablebase(bytes.NewReader([]byte("not a tablebase"))); err != vulpes.ErrInvalidTablebase {
+		t.Errorf("Reading an invalid tablebase gave %v", err)
+	}
+}
+
+func TestHash(t *testing.T) {
+	seen := map[ttt]bool{}
+	reachable(NewEmptyAI().State, seen)
+	hashes := map[uint64]ttt{}
+	for state := range seen {
+		if other, ok := hashes[state.Hash()]; ok {
+			t.Errorf("Hash collision: %v, %v", state, other)
+		}
+		hashes[state.Hash()] = state
+	}
+}
+
+func TestSolveGameTable(t *testing.T) {
+	seen := map[ttt]bool{}
+	reachable(NewEmptyAI().State, seen)
+	table := vulpes.NewTable(1 << 10)
+	for state := range seen {
+		_, score := vulpes.Search(state, 9, math.Inf(-1), math.Inf(1))
+		_, tableScore := vulpes.SolveGameTable(state, 9, table)
+		if score != tableScore {
+			t.Errorf("Table changed the score of:\n%v\n%v != %v", state, tableScore, score)
+		}
+	}
+}
+
+func TestPVS(t *testing.T) {
+	seen := map[ttt]bool{}
+	reachable(NewEmptyAI().State, seen)
+	for state := range seen {
+		for depth := uint(0); depth <= 9; depth++ {
+			_, score := vulpes.Search(state, depth, math.Inf(-1), math.Inf(1))
+			_, pvsScore := vulpes.SearchPVS(state, depth, math.Inf(-1), math.Inf(1))
+			if score != pvsScore {
+				t.Errorf("PVS changed the score of (depth %d):\n%v\n%v != %v", depth, state, pvsScore, score)
+			}
+		}
+	}
+	_, score := vulpes.SolveGame(NewEmptyAI().State, 9)
+	_, pvsScore := vulpes.SolveGamePVS(NewEmptyAI().State, 9)
+	if score != pvsScore {
+		t.Errorf("PVS changed the score of the empty board: %v != %v", pvsScore, score)
+	}
+}
+
+func TestMTDF(t *testing.T) {
+	for _, guess := range []float64{0, 1, -1, vulpes.WinScore} {
+		result, passes := vulpes.MTDF(NewEmptyAI().State, 9, guess)
+		if result.Score != 0 {
+			t.Errorf("Non-zero TTT score from guess %v: %v", guess, result.Score)
+		}
+		t.Logf("Guess %v took %d passes", guess, passes)
+	}
+	c := NewAI([9]int{1, 1, 0, -1, 1, 0, -1, 0, 0})
+	result, _ := vulpes.MTDF(c.State, 9, 0)
+	if ending, plies := vulpes.DecodeScore(result.Score); ending != vulpes.LOSS || plies != 2 {
+		t.Errorf("Expected a loss in 2, got %v in %v", ending, plies)
+	}
+}
+
+func TestAspiration(t *testing.T) {
+	result := vulpes.SolveAspiration(NewEmptyAI().State, 9, vulpes.Aspiration{Width: 1, Growth: 2})
+	if result.Score != 0 {
+		t.Errorf("Non-zero TTT score: %v", result.Score)
+	}
+	c := NewAI([9]int{1, 1, 0, -1, 1, 0, -1, 0, 0})
+	result = vulpes.SolveAspiration(c.State, 9, vulpes.Aspiration{Width: 1, Growth: 2})
+	if ending, plies := vulpes.DecodeScore(result.Score); ending != vulpes.LOSS || plies != 2 {
+		t.Errorf("Expected a loss in 2, got %v in %v", ending, plies)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	result := vulpes.SolveParallel(NewEmptyAI().State, 9, 3)
+	if result.Score != 0 {
+		t.Errorf("Non-zero TTT score: %v", result.Score)
+	}
+	c := NewAI([9]int{1, 1, 0, -1, -1, 0, 0, 0, 0})
+	result = vulpes.SolveParallel(c.State, 9, 3)
+	if ending, plies := vulpes.DecodeScore(result.Score); ending != vulpes.WIN || plies != 1 {
+		t.Errorf("Expected a win in 1, got %v in %v", ending, plies)
+	}
+	if result.Best != (ttt{[9]int{1, 1, 1, -1, -1, 0, 0, 0, 0}, false}) {
+		t.Errorf("Didn't take the win:\n%v", result.Best)
+	}
+}
+
+func TestLazySMP(t *testing.T) {
+	result := vulpes.SolveLazySMP(NewEmptyAI().State, 9, 4)
+	if result.Score != 0 {
+		t.Errorf("Non-zero TTT score: %v", result.Score)
+	}
+	c := NewAI([9]int{1, 1, 0, -1, 1, 0, -1, 0, 0})
+	result = vulpes.SolveLazySMP(c.State, 9, 4)
+	if ending, plies := vulpes.DecodeScore(result.Score); ending != vulpes.LOSS || plies != 2 {
+		t.Errorf("Expected a loss in 2, got %v in %v", ending, plies)
+	}
+}
+
+func TestMCTS(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	// X to move, and can win immediately
+	c := NewAI([9]int{1, 1, 0, -1, -1, 0, 0, 0, 0})
+	best, score := vulpes.SolveMCTS(c.State, vulpes.MCTSOptions{Iterations: 2000, Rand: r})
+	if best != (ttt{[9]int{1, 1, 1, -1, -1, 0, 0, 0, 0}, false}) {
+		t.Errorf("Didn't take the win:\n%v", best)
+	}
+	if score != 1 {
+		t.Errorf("Immediate win scored %v", score)
+	}
+	// X to move, and must block O
+	c = NewAI([9]int{1, 0, 0, -1, -1, 0, 1, 0, 0})
+	best, _ = vulpes.SolveMCTS(c.State, vulpes.MCTSOptions{Iterations: 10000, Rand: r})
+	if best != (ttt{[9]int{1, 0, 0, -1, -1, 1, 1, 0, 0}, false}) {
+		t.Errorf("Didn't block:\n%v", best)
+	}
+	// The empty board is a tie, so the score should be close to 0
+	_, score = vulpes.SolveMCTS(NewEmptyAI().State, vulpes.MCTSOptions{Iterations: 100000, Rand: r})
+	if math.Abs(score) > 0.5 {
+		t.Errorf("Empty board scored %v", score)
+	}
+}
+
 func BenchmarkAI(b *testing.B) {
 	for depth := uint(0); depth < 10; depth++ {
 		b.Run(fmt.Sprintf("Depth %d", depth), func(b *testing.B) {
@@ -0,0 +1,163 @@
+package ttt3
+
+import (
+	"github.com/argusdusty/vulpes"
+)
+
+// Players is the number of players taking turns.
+const Players = 3
+
+// size is the width and height of the board.
+const size = 4
+
+// lines lists the cells of every line of 3 on the board.
+var lines = func() [][3]int {
+	var lines [][3]int
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; col++ {
+			for _, dir := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+				endRow, endCol := row+2*dir[0], col+2*dir[1]
+				if endRow < 0 || endRow >= size || endCol < 0 || endCol >= size {
+					continue
+				}
+				lines = append(lines, [3]int{size*row + col, size*(row+dir[0]) + col + dir[1], size*endRow + endCol})
+			}
+		}
+	}
+	return lines
+}()
+
+// ttt3 is three-player Tic-Tac-Toe on a 4x4 board, won by the first player to get 3 in a row.
+type ttt3 struct {
+	// board holds 0 for an empty cell, or 1 more than the index of the player who took it.
+	board  [size * size]int
+	player int
+}
+
+func (t ttt3) Children() []vulpes.MultiplayerGame {
+	children := make([]vulpes.MultiplayerGame, 0, size*size)
+	for i := range t.board {
+		if t.board[i] == 0 {
+			child := ttt3{t.board, (t.player + 1) % Players}
+			child.board[i] = t.player + 1
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+func (t ttt3) Player() int {
+	return t.player
+}
+
+// Evaluate scores a win as 1, plus a bonus for each empty cell left so that quicker wins are preferred, and a tie as 1/3 each. Unfinished games are scored by the share of the lines still open to each player.
+func (t ttt3) Evaluate() (ended bool, scores []float64) {
+	scores = make([]float64, Players)
+	var empty int
+	for _, cell := range t.board {
+		if cell == 0 {
+			empty++
+		}
+	}
+	var open [Players]int
+	var total int
+	for _, line := range lines {
+		a, b, c := t.board[line[0]], t.board[line[1]], t.board[line[2]]
+		if a != 0 && a == b && b == c {
+			scores[a-1] = 1 + float64(empty)/100
+			return true, scores
+		}
+		// The line is open to a player if they're the only one in it
+		owner := 0
+		for _, cell := range []int{a, b, c} {
+			if cell != 0 && owner != 0 && cell != owner {
+				owner = -1
+				break
+			}
+			if cell != 0 {
+				owner = cell
+			}
+		}
+		if owner > 0 {
+			open[owner-1]++
+			total++
+		}
+	}
+	if empty == 0 {
+		for i := range scores {
+			scores[i] = 1.0 / Players
+		}
+		return true, scores
+	}
+	for i := range scores {
+		if total > 0 {
+			scores[i] = float64(open[i]) / float64(total) / 2
+		}
+	}
+	return false, scores
+}
+
+func (t ttt3) String() string {
+	out := ""
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; col++ {
+			out += string("_XOY"[t.board[size*row+col]])
+		}
+		if row != size-1 {
+			out += "\n"
+		}
+	}
+	return out
+}
+
+// AI uses vulpes to play three-player Tic-Tac-Toe
+type AI struct {
+	State ttt3
+}
+
+// NewEmptyAI returns a three-player Tic-Tac-Toe AI from an empty board
+func NewEmptyAI() *AI {
+	return &AI{}
+}
+
+// NewAI returns a three-player Tic-Tac-Toe AI from a given board, holding 0 for an empty cell, or 1 more than the index of the player who took it. Players take turns in order, starting from player 0.
+func NewAI(board [size * size]int) *AI {
+	var counts [Players]int
+	for _, cell := range board {
+		if cell < 0 || cell > Players {
+			panic("Invalid board")
+		}
+		if cell != 0 {
+			counts[cell-1]++
+		}
+	}
+	player := 0
+	for i := 1; i < Players; i++ {
+		if counts[i] > counts[0] || counts[i] < counts[0]-1 || counts[i] > counts[i-1] {
+			panic("Invalid board")
+		}
+		if counts[i] < counts[i-1] {
+			player = i
+		}
+	}
+	return &AI{State: ttt3{board, player}}
+}
+
+// MakeMove takes the best move (searching to the given depth with max^n) and plays it, updating State, and returns the scores of every player. If the game is over, it returns the final scores, and makes no changes to State.
+func (C *AI) MakeMove(depth uint) []float64 {
+	best, scores := vulpes.SolveMaxN(C.State, depth)
+	C.State = best.(ttt3)
+	return scores
+}
+
+// MakeMoveParanoid takes the best move (searching to the given depth, assuming the other players are working together against the current one) and plays it, updating State, and returns the score of the current player. If the game is over, it returns the final score, and makes no changes to State.
+func (C *AI) MakeMoveParanoid(depth uint) float64 {
+	best, score := vulpes.SolveParanoid(C.State, depth)
+	C.State = best.(ttt3)
+	return score
+}
+
+// String returns a string representation of the game board
+func (C *AI) String() string {
+	return C.State.String()
+}
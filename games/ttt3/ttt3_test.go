@@ -0,0 +1,148 @@
+package ttt3
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/argusdusty/vulpes"
+)
+
+// minimax scores a state for the given player by a plain paranoid search of the whole tree to the given depth.
+func minimax(state vulpes.MultiplayerGame, depth uint, player int) float64 {
+	ended, scores := state.Evaluate()
+	if ended || depth == 0 {
+		return scores[player]
+	}
+	best := math.Inf(1)
+	if state.Player() == player {
+		best = math.Inf(-1)
+	}
+	for _, child := range state.Children() {
+		score := minimax(child, depth-1, player)
+		if state.Player() == player {
+			best = math.Max(best, score)
+		} else {
+			best = math.Min(best, score)
+		}
+	}
+	return best
+}
+
+// randomState returns a random unfinished position after n moves.
+func randomState(r *rand.Rand, n int) ttt3 {
+	for {
+		var t ttt3
+		for i := 0; i < n; i++ {
+			children := t.Children()
+			t = children[r.Intn(len(children))].(ttt3)
+		}
+		if ended, _ := t.Evaluate(); !ended {
+			return t
+		}
+	}
+}
+
+func TestLines(t *testing.T) {
+	if len(lines) != 24 {
+		t.Errorf("Found %d lines, want 24", len(lines))
+	}
+}
+
+func TestNewAI(t *testing.T) {
+	for _, test := range []struct {
+		board  [16]int
+		player int
+	}{
+		{[16]int{}, 0},
+		{[16]int{1}, 1},
+		{[16]int{1, 2}, 2},
+		{[16]int{1, 2, 3}, 0},
+	} {
+		if c := NewAI(test.board); c.State.Player() != test.player {
+			t.Errorf("NewAI(%v) has player %d to move, want %d", test.board, c.State.Player(), test.player)
+		}
+	}
+}
+
+func TestWin(t *testing.T) {
+	// X can win at 2, ahead of the threats from O and Y
+	board := [16]int{
+		1, 1, 0, 0,
+		2, 2, 0, 0,
+		3, 3, 0, 0,
+		0, 0, 0, 0,
+	}
+	want := board
+	want[2] = 1
+	c := NewAI(board)
+	if scores := c.MakeMove(3); c.State.board != want || scores[0] < 1 {
+		t.Errorf("Max^n didn't take the win, scoring %v:\n%v", scores, c)
+	}
+	c = NewAI(board)
+	if score := c.MakeMoveParanoid(3); c.State.board != want || score < 1 {
+		t.Errorf("Paranoid didn't take the win, scoring %v:\n%v", score, c)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	// O will win at 5 unless X blocks it
+	board := [16]int{
+		1, 0, 0, 3,
+		2, 0, 2, 0,
+		0, 0, 0, 0,
+		3, 0, 0, 1,
+	}
+	want := board
+	want[5] = 1
+	c := NewAI(board)
+	if scores := c.MakeMove(2); c.State.board != want {
+		t.Errorf("Max^n didn't block, scoring %v:\n%v", scores, c)
+	}
+	c = NewAI(board)
+	if score := c.MakeMoveParanoid(2); c.State.board != want {
+		t.Errorf("Paranoid didn't block, scoring %v:\n%v", score, c)
+	}
+}
+
+func TestParanoid(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		state := randomState(r, r.Intn(10))
+		for depth := uint(1); depth <= 3; depth++ {
+			want := minimax(state, depth, state.Player())
+			if _, score := vulpes.SolveParanoid(state, depth); score != want {
+				t.Errorf("SolveParanoid(%d) = %v, want %v:\n%v", depth, score, want, state)
+			}
+		}
+	}
+}
+
+func TestGame(t *testing.T) {
+	c := NewEmptyAI()
+	for moves := 0; ; moves++ {
+		if ended, _ := c.State.Evaluate(); ended {
+			break
+		}
+		if moves >= 16 {
+			t.Fatalf("Game didn't end:\n%v", c)
+		}
+		if moves%2 == 0 {
+			c.MakeMove(3)
+		} else {
+			c.MakeMoveParanoid(4)
+		}
+	}
+}
+
+func BenchmarkMaxN(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		vulpes.SolveMaxN(ttt3{}, 4)
+	}
+}
+
+func BenchmarkParanoid(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		vulpes.SolveParanoid(ttt3{}, 4)
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/argusdusty/vulpes
 
-go 1.14
+go 1.18
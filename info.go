@@ -0,0 +1,77 @@
+package vulpes
+
+import (
+	"fmt"
+	"time"
+)
+
+// Info reports the progress of a search, like the "info" lines of a UCI chess engine.
+type Info = InfoOf[Game]
+
+// InfoOf is the generic form of Info, reporting the progress of a SearcherOf[G].
+type InfoOf[G any] struct {
+	// Depth is the depth being searched, or just completed.
+	Depth uint
+	// Completed is set when the search to Depth has been completed, and otherwise the search to Depth is still in progress.
+	Completed bool
+	// Best is the best child of the root found so far at Depth, or if there's none yet, at the last completed depth. It's nil (or the zero value of G) if no child has been found yet.
+	Best G
+	// Score is the score of Best. Until Depth is completed, it may only be a bound on the score, from a search window which it fell outside of.
+	Score float64
+	// PV is the principal variation starting with Best.
+	PV []G
+	// Nodes is the number of nodes visited so far, by the main search.
+	Nodes uint64
+	// Elapsed is the time since the search started.
+	Elapsed time.Duration
+	// NodesPerSecond is the number of nodes visited per second so far.
+	NodesPerSecond float64
+}
+
+// String formats the info as a line of text, such as "depth 12 score 34 nodes 56789 nps 123456 time 460ms". Forced endings are reported as "mate N", or "mate -N" when losing, in plies.
+func (i InfoOf[G]) String() string {
+	score := fmt.Sprintf("score %v", i.Score)
+	switch ending, plies := DecodeScore(i.Score); ending {
+	case WIN:
+		score = fmt.Sprintf("mate %d", plies)
+	case LOSS:
+		score = fmt.Sprintf("mate -%d", plies)
+	}
+	return fmt.Sprintf("depth %d %s nodes %d nps %.0f time %v", i.Depth, score, i.Nodes, i.NodesPerSecond, i.Elapsed.Round(time.Millisecond))
+}
+
+// info reports the progress of a search in progress to onInfo.
+func (s *searcher[G]) info() {
+	s.lastInfo = time.Now()
+	info := s.newInfo(s.depth)
+	if len(s.pv) > 0 && len(s.pv[0]) > 0 {
+		info.Best, info.Score, info.PV = s.pv[0][0], s.rootScore, append([]G(nil), s.pv[0]...)
+	} else if s.lasted {
+		info.Best, info.Score, info.PV = s.last.Best, s.last.Score, s.last.PV
+	}
+	s.onInfo(info)
+}
+
+// newInfo returns the info for the search at the given depth, other than its results.
+func (s *searcher[G]) newInfo(depth uint) InfoOf[G] {
+	elapsed := time.Since(s.start)
+	info := InfoOf[G]{Depth: depth, Nodes: s.nodes, Elapsed: elapsed}
+	if elapsed > 0 {
+		info.NodesPerSecond = float64(s.nodes) / elapsed.Seconds()
+	}
+	return info
+}
+
+// complete reports the result of a completed depth to the callbacks.
+func (s *searcher[G]) complete(result ResultOf[G]) {
+	s.last, s.lasted = result, true
+	if s.onDepth != nil {
+		s.onDepth(result)
+	}
+	if s.onInfo != nil {
+		s.lastInfo = time.Now()
+		info := s.newInfo(result.Depth)
+		info.Completed, info.Best, info.Score, info.PV = true, result.Best, result.Score, result.PV
+		s.onInfo(info)
+	}
+}
@@ -0,0 +1,145 @@
+package vulpes
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// DefaultMCTSIterations is the number of playouts SolveMCTS runs when given neither an iteration nor a time budget.
+const DefaultMCTSIterations = 10000
+
+// maxPlayout limits the length of a random playout, after which the game is treated as a tie.
+const maxPlayout = 1 << 12
+
+// MCTSOptions configures SolveMCTS.
+type MCTSOptions struct {
+	// Exploration is the UCT exploration constant, trading off the exploration of rarely visited children against the exploitation of good ones. If it's 0, math.Sqrt2 is used.
+	Exploration float64
+	// Iterations limits the number of playouts, if positive.
+	Iterations int
+	// Time limits the time spent searching, if positive. At least one playout is always run.
+	Time time.Duration
+	// Rand is the source of randomness for the playouts. If it's nil, a source seeded from the current time is used.
+	Rand *rand.Rand
+}
+
+// mctsNode is a node of the tree grown by SolveMCTS.
+type mctsNode struct {
+	state    Game
+	parent   *mctsNode
+	children []*mctsNode
+	// untried holds the children of the state which don't yet have a node.
+	untried []Game
+	visits  float64
+	// reward is the total reward of the playouts through this node, for the player who moved into it.
+	reward float64
+}
+
+func newMCTSNode(state Game, parent *mctsNode) *mctsNode {
+	n := &mctsNode{state: state, parent: parent}
+	if ending, _ := state.Evaluate(); ending == UNFINISHED {
+		n.untried = state.Children()
+	}
+	return n
+}
+
+// selectChild returns the child with the highest upper confidence bound (UCT).
+func (n *mctsNode) selectChild(exploration float64) *mctsNode {
+	var best *mctsNode
+	bestValue := math.Inf(-1)
+	logVisits := math.Log(n.visits)
+	for _, child := range n.children {
+		value := child.reward/child.visits + exploration*math.Sqrt(logVisits/child.visits)
+		if value > bestValue {
+			best, bestValue = child, value
+		}
+	}
+	return best
+}
+
+// reward converts an ending into a reward: 1 for a win, 1/2 for a tie, and 0 for a loss.
+func reward(ending int) float64 {
+	switch ending {
+	case WIN:
+		return 1
+	case LOSS:
+		return 0
+	}
+	return 0.5
+}
+
+// playout plays random moves from state until the game ends, returning the reward for the current player in state.
+func playout(state Game, r *rand.Rand) float64 {
+	for ply := 0; ply < maxPlayout; ply++ {
+		ending, _ := state.Evaluate()
+		if ending != UNFINISHED {
+			if ply%2 == 1 {
+				return 1 - reward(ending)
+			}
+			return reward(ending)
+		}
+		children := state.Children()
+		state = children[r.Intn(len(children))]
+	}
+	return 0.5
+}
+
+// SolveMCTS takes a starting node for the game, and returns the best child node and its score, using Monte Carlo Tree Search with UCT. Rather than relying on a heuristic, it grows a tree of the most promising moves, scoring them by random playouts to the end of the game, so only the ending from Evaluate is used.
+// The best child is the one visited most. Its score is the average outcome of its playouts, from 1 for a win, to -1 for a loss, for the current player.
+func SolveMCTS(state Game, opts MCTSOptions) (Game, float64) {
+	if ending, _ := state.Evaluate(); ending != UNFINISHED {
+		return state, 2*reward(ending) - 1
+	}
+	exploration := opts.Exploration
+	if exploration == 0 {
+		exploration = math.Sqrt2
+	}
+	iterations := opts.Iterations
+	if iterations <= 0 && opts.Time <= 0 {
+		iterations = DefaultMCTSIterations
+	}
+	var deadline time.Time
+	if opts.Time > 0 {
+		deadline = time.Now().Add(opts.Time)
+	}
+	r := opts.Rand
+	if r == nil {
+		r = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	root := newMCTSNode(state, nil)
+	for i := 0; iterations <= 0 || i < iterations; i++ {
+		if !deadline.IsZero() && i > 0 && time.Now().After(deadline) {
+			break
+		}
+		// Selection
+		n := root
+		for len(n.untried) == 0 && len(n.children) > 0 {
+			n = n.selectChild(exploration)
+		}
+		// Expansion
+		if len(n.untried) > 0 {
+			j := r.Intn(len(n.untried))
+			child := newMCTSNode(n.untried[j], n)
+			n.untried[j] = n.untried[len(n.untried)-1]
+			n.untried = n.untried[:len(n.untried)-1]
+			n.children = append(n.children, child)
+			n = child
+		}
+		// Simulation, scored for the player who moved into n
+		value := 1 - playout(n.state, r)
+		// Backpropagation
+		for ; n != nil; n = n.parent {
+			n.visits++
+			n.reward += value
+			value = 1 - value
+		}
+	}
+	best := root.children[0]
+	for _, child := range root.children[1:] {
+		if child.visits > best.visits {
+			best = child
+		}
+	}
+	return best.state, 2*best.reward/best.visits - 1
+}
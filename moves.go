@@ -0,0 +1,143 @@
+package vulpes
+
+import (
+	"math"
+)
+
+// Move identifies one of the moves possible from a state of a MoveGame. Its meaning is up to the game, but it should be small and non-negative, as it also identifies the move to the killer and history heuristics, like the MoveID of a Mover.
+type Move int
+
+// MoveGame is an optional interface a Game may implement to be searched by making and unmaking moves on a single state, changing it in place, rather than by allocating a slice of children, and boxing each of them in an interface. As Play and Undo change the state, it must be implemented by a pointer to the state, or some other reference.
+// The root of a search is still expanded by Children, so that the state passed to the search is left alone, and each child can be searched independently. So the children must be MoveGames themselves, each a separate copy, and below them, every state is searched in place. Children is also used to turn the principal variation back into states.
+type MoveGame = MoveGameOf[Game]
+
+// MoveGameOf is the generic form of MoveGame, for a GameOf[G].
+type MoveGameOf[G any] interface {
+	GameOf[G]
+	// Moves appends the possible moves from this state to buf, returning the extended slice. They must be listed in the same order as the children they lead to are returned by Children.
+	Moves(buf []Move) []Move
+	// Play makes a move returned by Moves, changing the state to the child it leads to.
+	Play(m Move)
+	// Undo takes back the move last made by Play, which is given again, restoring the state exactly.
+	Undo(m Move)
+}
+
+// TacticalMover is an optional interface a MoveGame which is also Tactical may implement to have its tactical moves played in place by the quiescence search, rather than allocating its tactical children.
+type TacticalMover = TacticalMoverOf[Game]
+
+// TacticalMoverOf is the generic form of TacticalMover, for a GameOf[G].
+type TacticalMoverOf[G any] interface {
+	MoveGameOf[G]
+	TacticalOf[G]
+	// TacticalMoves appends the moves leading to the tactical children to buf, returning the extended slice. They must be a subset of the moves listed by Moves.
+	TacticalMoves(buf []Move) []Move
+}
+
+// playMoves searches the moves of a MoveGame, given as both the state and its MoveGameOf view, making and unmaking each of them in place. It picks up the search of a node from negamax, once it's been found to need searching, taking the hash of the state if hashed, and the index of the best move from a previous search, or -1.
+func (s *searcher[G]) playMoves(state G, g MoveGameOf[G], depth uint, alpha, beta float64, ply int, hashed bool, hash uint64, bestIndex int) float64 {
+	moves := s.moveBuf(ply, g.Moves)
+	order := s.orderMoves(g, moves, depth, ply, bestIndex)
+	var tmpScore float64
+	origAlpha := alpha
+	bestScore := math.Inf(-1)
+	for i, moveScore := range order {
+		m := moves[moveScore.moveIndex]
+		g.Play(m)
+		tmpScore = s.searchChild(state, i, depth, alpha, beta, ply)
+		g.Undo(m)
+		if s.stopped {
+			return alpha
+		}
+		bestScore = math.Max(bestScore, tmpScore)
+		if tmpScore > alpha {
+			alpha = tmpScore
+			bestIndex = moveScore.moveIndex
+			s.playedPV(m, ply)
+			if beta <= alpha {
+				s.moves.cutoffID(int(m), depth, ply)
+				s.stats.cutoff(depth, i)
+				s.store(hashed, hash, bestScore, depth, ply, bestIndex, lowerBound)
+				if s.failSoft {
+					return bestScore
+				}
+				return beta
+			}
+		}
+	}
+	if bestScore <= origAlpha {
+		s.store(hashed, hash, bestScore, depth, ply, bestIndex, upperBound)
+	} else {
+		s.store(hashed, hash, bestScore, depth, ply, bestIndex, exactBound)
+	}
+	if s.failSoft {
+		return bestScore
+	}
+	return alpha
+}
+
+// moveBuf returns the moves listed by the given function, appended to the buffer kept for the ply.
+func (s *searcher[G]) moveBuf(ply int, list func(buf []Move) []Move) []Move {
+	for len(s.moveBufs) <= ply {
+		s.moveBufs = append(s.moveBufs, nil)
+		s.scoreBufs = append(s.scoreBufs, nil)
+	}
+	s.moveBufs[ply] = list(s.moveBufs[ply][:0])
+	return s.moveBufs[ply]
+}
+
+// playedPV records a move played in place as the start of the principal variation at the ply. The line is kept as moves, followed by any states found past the last MoveGame, and only turned into states by line, once it reaches a node searched by Children.
+func (s *searcher[G]) playedPV(m Move, ply int) {
+	s.pvMoves[ply] = append(append(s.pvMoves[ply][:0], m), s.pvMoves[ply+1]...)
+	s.pv[ply] = append(s.pv[ply][:0], s.pv[ply+1]...)
+}
+
+// orderMoves is like orderChildren, but for the moves of a MoveGame, which are played to evaluate them. The order is kept in a buffer for the ply, to avoid allocating it.
+func (s *searcher[G]) orderMoves(state MoveGameOf[G], moves []Move, depth uint, ply int, bestIndex int) moveScores {
+	moveScores := s.scoreBufs[ply][:0]
+	for i := range moves {
+		moveScores = append(moveScores, moveScore{i, 0.0})
+	}
+	s.scoreBufs[ply] = moveScores
+	if depth > 1 {
+		// Pre-sort the possible moves by their score to speed up the pruning
+		for i, m := range moves {
+			state.Play(m)
+			ending, heuristic := state.Evaluate()
+			state.Undo(m)
+			moveScores[i].moveScore = -staticScore(ending, heuristic, ply+1)
+		}
+		moveScores.sortStable()
+	} else {
+		s.moves.order(func(i int) int { return int(moves[i]) }, ply, moveScores)
+	}
+	if bestIndex >= 0 && bestIndex < len(moves) {
+		// Try the best move from a previous search first
+		for i := range moveScores {
+			if moveScores[i].moveIndex == bestIndex {
+				moveScores.promote(i)
+				break
+			}
+		}
+	}
+	return moveScores
+}
+
+// line returns the principal variation following a child searched at the given ply, turning the moves found below a MoveGame into states.
+func (s *searcher[G]) line(child G, ply int) []G {
+	if _, ok := any(child).(MoveGameOf[G]); !ok || len(s.pvMoves[ply]) == 0 {
+		return s.pv[ply]
+	}
+	line := make([]G, 0, len(s.pvMoves[ply])+len(s.pv[ply]))
+	state := child
+	for _, m := range s.pvMoves[ply] {
+		moves, children := any(state).(MoveGameOf[G]).Moves(nil), state.Children()
+		for i := range moves {
+			if moves[i] == m {
+				state = children[i]
+				break
+			}
+		}
+		line = append(line, state)
+	}
+	return append(line, s.pv[ply]...)
+}
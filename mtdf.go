@@ -0,0 +1,50 @@
+package vulpes
+
+import (
+	"math"
+)
+
+// MTDF searches state to the given depth with MTD(f): a sequence of null window, fail-soft searches, each narrowing the bounds on the score, starting from a guess at it (such as the score from a shallower search). It returns the result, along with the number of passes taken, and produces the same score as Solve.
+// Each pass reuses the work of the previous ones through a transposition table of DefaultTableSize entries, so the state should implement Hasher. If a ChanceNode is found, the first pass is followed by a search with the full window, as the averaged scores are too finely spread for the bounds to converge.
+func MTDF(state Game, depth uint, guess float64) (Result, int) {
+	s := newSearcher[Game](newTable(state))
+	s.failSoft = true
+	return s.mtdf(state, depth, guess)
+}
+
+func (s *searcher[G]) mtdf(state G, depth uint, guess float64) (ResultOf[G], int) {
+	lower, upper := math.Inf(-1), math.Inf(1)
+	var result, last ResultOf[G]
+	// failedHigh is set once a search has failed high, giving result
+	var failedHigh bool
+	var passes int
+	score := guess
+	for lower < upper {
+		beta := score
+		if score == lower {
+			beta = math.Nextafter(score, math.Inf(1))
+		}
+		last = s.solve(state, depth, math.Nextafter(beta, math.Inf(-1)), beta)
+		passes++
+		if s.stopped {
+			return last, passes
+		}
+		if s.chanced {
+			result = s.solve(state, depth, math.Inf(-1), math.Inf(1))
+			return result, passes + 1
+		}
+		score = last.Score
+		if score < beta {
+			upper = score
+		} else {
+			// Only searches which fail high find the best move
+			lower = score
+			result, failedHigh = last, true
+		}
+	}
+	if !failedHigh {
+		result = last
+	}
+	result.Score = score
+	return result, passes
+}
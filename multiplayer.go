@@ -0,0 +1,83 @@
+package vulpes
+
+import (
+	"math"
+)
+
+// MultiplayerGame describes a turn-based game for any number of players, which needn't be zero-sum.
+type MultiplayerGame interface {
+	// Children returns the child nodes from this one. If the game is not ended, this must return at least 1 child.
+	Children() []MultiplayerGame
+	// Player returns the index of the player whose turn it is, into the scores from Evaluate.
+	Player() int
+	// Evaluate returns whether the game has ended, and a score for every player, the higher the better. The scores are final if the game has ended, and otherwise heuristic. There must be a score for every player, in the same order throughout the game.
+	Evaluate() (ended bool, scores []float64)
+}
+
+// maxN is the max^n recursion behind SolveMaxN.
+func maxN(state MultiplayerGame, depth uint) (MultiplayerGame, []float64) {
+	ended, scores := state.Evaluate()
+	if ended || depth == 0 {
+		return state, scores
+	}
+	player := state.Player()
+	var bestChild MultiplayerGame
+	var bestScores []float64
+	for _, child := range state.Children() {
+		_, childScores := maxN(child, depth-1)
+		if bestScores == nil || childScores[player] > bestScores[player] {
+			bestChild, bestScores = child, childScores
+		}
+	}
+	if bestChild == nil {
+		// No possible moves, so return the current state.
+		return state, scores
+	}
+	return bestChild, bestScores
+}
+
+// SolveMaxN takes a starting node for the game, and returns the best child node and the scores of every player, after searching to the specified depth with max^n: each player picks the child with the best score for themselves. Ties are broken in favour of the first child.
+// Max^n can't prune the tree as alpha-beta does, so it's much slower to search deeply than SolveParanoid.
+func SolveMaxN(state MultiplayerGame, depth uint) (MultiplayerGame, []float64) {
+	return maxN(state, depth)
+}
+
+// paranoid is the alpha-beta recursion behind SolveParanoid, returning the score of the given player.
+func paranoid(state MultiplayerGame, depth uint, alpha, beta float64, player int) (MultiplayerGame, float64) {
+	ended, scores := state.Evaluate()
+	if ended || depth == 0 {
+		return state, scores[player]
+	}
+	maximise := state.Player() == player
+	var bestChild MultiplayerGame
+	for _, child := range state.Children() {
+		_, score := paranoid(child, depth-1, alpha, beta, player)
+		if maximise && score > alpha {
+			alpha = score
+			bestChild = child
+		} else if !maximise && score < beta {
+			beta = score
+			bestChild = child
+		}
+		if beta <= alpha {
+			break
+		}
+		if bestChild == nil {
+			// Take the first child, in case all the children are terrible.
+			bestChild = child
+		}
+	}
+	if bestChild == nil {
+		// No possible moves, so return the current state.
+		bestChild = state
+	}
+	if maximise {
+		return bestChild, alpha
+	}
+	return bestChild, beta
+}
+
+// SolveParanoid takes a starting node for the game, and returns the best child node and the score of the current player, after searching to the specified depth under the paranoid assumption: that every other player is out to minimise the current player's score. This reduces the game to a two-player one, which is searched by alpha-beta.
+func SolveParanoid(state MultiplayerGame, depth uint) (MultiplayerGame, float64) {
+	return paranoid(state, depth, math.Inf(-1), math.Inf(1), state.Player())
+}
@@ -0,0 +1,96 @@
+package vulpes
+
+import (
+	"math"
+)
+
+// Line is one of the best lines of play found by a search with Options.MultiPV.
+type Line = LineOf[Game]
+
+// LineOf is the generic form of Line, for a GameOf[G].
+type LineOf[G any] struct {
+	// Best is the child of the root starting the line.
+	Best G
+	// Score is the exact score of Best, from the perspective of the current player at the root.
+	Score float64
+	// PV is the principal variation starting with Best.
+	PV []G
+}
+
+// solveLines searches an unfinished state to the given depth for its best multiPV children. Rather than searching every child with a full window, each is searched with a window from the score of the multiPV'th best child so far, so that the score of a child is exact if it's among the best, and otherwise just shows that it isn't.
+func (s *searcher[G]) solveLines(state G, depth uint) ResultOf[G] {
+	s.depth = depth
+	result := ResultOf[G]{Best: state, Score: math.Inf(-1), Depth: depth}
+	if !s.enter(0) {
+		result.Nodes, result.Stats = s.nodes, s.statistics()
+		return result
+	}
+	var hash uint64
+	var hashed bool
+	bestIndex := s.rootBest
+	if h, ok := any(state).(Hasher); ok && s.table != nil {
+		hash, hashed = h.Hash(), true
+		if entry, found := s.table.probe(hash); found && bestIndex < 0 {
+			bestIndex = int(entry.best)
+		}
+	}
+	children := state.Children()
+	order := s.orderChildren(children, depth, 0, bestIndex)
+	// Search the best lines from the previous search first, in their order
+	for k := len(s.rootLines) - 1; k >= 0; k-- {
+		for i := range order {
+			if order[i].moveIndex == s.rootLines[k] {
+				order.promote(i)
+				break
+			}
+		}
+	}
+	var lines []LineOf[G]
+	var indices []int
+	for i, moveScore := range order {
+		child := children[moveScore.moveIndex]
+		alpha, n := math.Inf(-1), 0
+		if len(lines) == s.multiPV {
+			// Until there are enough lines, every child is searched fully, so PVS and reductions only start from then
+			alpha, n = lines[len(lines)-1].Score, i
+		}
+		score := s.searchChild(child, n, depth, alpha, math.Inf(1), 0)
+		if s.stopped {
+			break
+		}
+		if score <= alpha {
+			continue
+		}
+		// Insert the line after any with the same score, dropping the worst if there are too many
+		j := len(lines)
+		for j > 0 && lines[j-1].Score < score {
+			j--
+		}
+		line := LineOf[G]{Best: child, Score: score, PV: append([]G{child}, s.line(child, 1)...)}
+		lines = append(lines[:j], append([]LineOf[G]{line}, lines[j:]...)...)
+		indices = append(indices[:j], append([]int{moveScore.moveIndex}, indices[j:]...)...)
+		if len(lines) > s.multiPV {
+			lines, indices = lines[:s.multiPV], indices[:s.multiPV]
+		}
+		if j == 0 {
+			s.rootBest, s.rootScore = moveScore.moveIndex, score
+			s.pv[0] = append(s.pv[0][:0], line.PV...)
+		}
+	}
+	if len(lines) == 0 {
+		// Stopped before any child was fully searched
+		result.Best = children[order[0].moveIndex]
+	} else {
+		if !s.stopped {
+			s.rootLines = indices
+			s.store(hashed, hash, lines[0].Score, depth, 0, indices[0], exactBound)
+		}
+		for k := range lines {
+			lines[k].PV = s.extendPV(lines[k].PV, depth)
+		}
+		result.Best, result.Score, result.Lines = lines[0].Best, lines[0].Score, lines
+		result.PV = append([]G(nil), lines[0].PV...)
+	}
+	result.Nodes, result.Stats = s.nodes, s.statistics()
+	return result
+}
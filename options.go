@@ -0,0 +1,254 @@
+package vulpes
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Algorithm selects the search algorithm used by a Searcher.
+type Algorithm int
+
+const (
+	// AlphaBeta is Negamax with alpha-beta pruning, as used by Solve.
+	AlphaBeta Algorithm = iota
+	// PVS is Principal Variation Search, as used by SolveGamePVS.
+	PVS
+	// MTDf searches with a sequence of null windows, as MTDF does, starting from the score of the previous depth when deepening, and 0 otherwise.
+	MTDf
+	// LazySMP searches with several goroutines sharing a transposition table, as SolveLazySMP does.
+	LazySMP
+)
+
+// Options configures a Searcher. The zero value searches to depth 0, just evaluating the state.
+type Options = OptionsOf[Game]
+
+// OptionsOf is the generic form of Options, configuring a SearcherOf[G].
+type OptionsOf[G any] struct {
+	// Depth is the depth to search to. If Time or Nodes is set, the search is deepened one ply at a time up to Depth, or without limit if it's 0, until the limits are reached or the game has been searched to the end.
+	Depth uint
+	// Time limits the time spent searching, if positive, as in SolveTimed.
+	Time time.Duration
+	// Nodes limits the number of nodes searched, if positive. It's checked by the main search, so doesn't count the nodes of LazySMP's helpers.
+	Nodes uint64
+	// TableSize is the number of entries in the transposition table used for states implementing Hasher, or DefaultTableSize if it's 0. If it's negative, no table is used.
+	TableSize int
+	// Algorithm is the search algorithm to use.
+	Algorithm Algorithm
+	// Threads is the number of goroutines searching with LazySMP, or GOMAXPROCS if it's not positive.
+	Threads int
+	// Aspiration configures the aspiration windows used when deepening. Setting it makes the search deepen even if neither Time nor Nodes is set.
+	Aspiration Aspiration
+	// Random, if positive, makes the search choose its move at random from the children of the root scoring within Random of the best, so that it doesn't always play the same way. Each child is checked with a null window search, and the Score of the result is still that of the best child. Forced endings are never randomised. The chosen child is searched again with a full window for its PV. The checks and this search count towards Time and Nodes, which keep a quarter of each for them. If the limits or ctx stop the checks, the best child is chosen after all, and if they stop the search of the chosen child, its PV is just the child. Random is ignored when MultiPV is greater than 1, as the Lines already give the exact scores of the best children to choose from.
+	Random float64
+	// Rand is the source of randomness for Random. If it's nil, a source seeded from the current time is used.
+	Rand *rand.Rand
+	// OnDepth, if non-nil, is called with the result of each depth completed by the search.
+	OnDepth func(ResultOf[G])
+	// OnInfo, if non-nil, is called with the progress of the search after each depth completed, and every InfoInterval while searching, if it's positive. It's called from the searching goroutine, so should return quickly.
+	OnInfo func(InfoOf[G])
+	// InfoInterval is the time between reports of progress to OnInfo during the search of a depth. The time is only checked every thousand or so nodes, so reports may come late.
+	InfoInterval time.Duration
+	// Tablebase, if non-nil, scores the positions it holds below the root exactly, rather than searching them. Positions missing from it, such as those before its starting state, are searched as usual.
+	Tablebase *Tablebase
+	// MultiPV, if greater than 1, makes the search find the best MultiPV children of the root, rather than just the best, each with its exact score and principal variation, in the Lines of the result. Each child is searched with a window starting from the score of the MultiPV'th best child so far, so the rest are pruned as usual. The root needs these windows of its own, so Aspiration isn't used, and MTDf searches as AlphaBeta.
+	MultiPV int
+
+	// NullMove enables null-move pruning at states implementing NullMover.
+	NullMove bool
+	// NullMoveReduction is the number of plies beyond the pass itself to reduce the search after a null move by, or DefaultNullMoveReduction if 0.
+	NullMoveReduction uint
+	// LateMoveReductions enables late move reductions: the children ordered late are first searched to a reduced depth with a null window, and only searched to the full depth if that shows they might be the best so far.
+	LateMoveReductions bool
+	// LateMoveStart is the number of children searched to the full depth before reductions start, or DefaultLateMoveStart if 0.
+	LateMoveStart int
+	// LateMoveReduction is the number of plies late moves are reduced by, or DefaultLateMoveReduction if 0.
+	LateMoveReduction uint
+}
+
+// withDefaults returns the options with the defaults filled in.
+func (o OptionsOf[G]) withDefaults() OptionsOf[G] {
+	if o.NullMoveReduction == 0 {
+		o.NullMoveReduction = DefaultNullMoveReduction
+	}
+	if o.LateMoveStart == 0 {
+		o.LateMoveStart = DefaultLateMoveStart
+	}
+	if o.LateMoveReduction == 0 {
+		o.LateMoveReduction = DefaultLateMoveReduction
+	}
+	return o
+}
+
+// Searcher searches games with a fixed set of Options, keeping its transposition table from one search to the next, so that a game played move by move can reuse the work of earlier searches. A Searcher must not be used by more than one search at a time.
+type Searcher = SearcherOf[Game]
+
+// SearcherOf is the generic form of Searcher, searching states of the concrete type G.
+type SearcherOf[G GameOf[G]] struct {
+	opts  OptionsOf[G]
+	table *Table
+	// tabled is set once the table has been created, for the first state searched.
+	tabled bool
+}
+
+// NewSearcher returns a Searcher using the given options.
+func NewSearcher(opts Options) *Searcher {
+	return NewSearcherOf(opts)
+}
+
+// NewSearcherOf returns a SearcherOf[G] using the given options.
+func NewSearcherOf[G GameOf[G]](opts OptionsOf[G]) *SearcherOf[G] {
+	return &SearcherOf[G]{opts: opts.withDefaults()}
+}
+
+// Options returns the options used by the Searcher, with the defaults filled in.
+func (sr *SearcherOf[G]) Options() OptionsOf[G] {
+	return sr.opts
+}
+
+// Table returns the transposition table used by the Searcher, which is nil until the first search of a state implementing Hasher.
+func (sr *SearcherOf[G]) Table() *Table {
+	return sr.table
+}
+
+// deepens reports whether the search is deepened one ply at a time, rather than searching straight to Depth.
+func (sr *SearcherOf[G]) deepens() bool {
+	o := sr.opts
+	if o.Time <= 0 && o.Nodes == 0 && o.Depth == 0 {
+		return false
+	}
+	return o.Time > 0 || o.Nodes > 0 || o.Aspiration.Width > 0 || o.Algorithm == LazySMP
+}
+
+// begin returns a searcher for a search of state, as configured by the options, creating the table for the first state searched.
+func (sr *SearcherOf[G]) begin(ctx context.Context, state G) *searcher[G] {
+	if !sr.tabled {
+		sr.tabled = true
+		if _, ok := any(state).(Hasher); ok && sr.opts.TableSize >= 0 {
+			size := sr.opts.TableSize
+			if size == 0 {
+				size = DefaultTableSize
+			}
+			if sr.opts.Algorithm == LazySMP {
+				sr.table = NewSharedTable(size)
+			} else {
+				sr.table = NewTable(size)
+			}
+		}
+	}
+	s := newSearcher[G](sr.table)
+	s.configure(sr.opts)
+	if ctx != nil && ctx.Done() != nil {
+		s.ctx = ctx
+	}
+	if sr.opts.Time > 0 {
+		s.deadline = time.Now().Add(sr.opts.Time)
+	}
+	return s
+}
+
+// Search searches state as configured by the options, returning the result. If ctx is done first, the search gives up, returning ctx.Err() with the result of the deepest completed search, or when not deepening, the best child found so far (out of those completely searched, or else the first to be searched).
+func (sr *SearcherOf[G]) Search(ctx context.Context, state G) (ResultOf[G], error) {
+	s := sr.begin(ctx, state)
+	deadline, maxNodes := s.deadline, s.maxNodes
+	random := sr.opts.Random > 0 && s.multiPV <= 1
+	if random {
+		// Leave a quarter of the limits for checking the other children when randomising
+		if !deadline.IsZero() {
+			s.deadline = deadline.Add(-sr.opts.Time / 4)
+		}
+		s.maxNodes -= maxNodes / 4
+	}
+	var result ResultOf[G]
+	switch {
+	case sr.deepens() && sr.opts.Algorithm == LazySMP:
+		result = s.lazySMP(state, sr.opts.Threads)
+	case sr.deepens():
+		result = s.deepen(state)
+	default:
+		s.stoppable = s.ctx != nil
+		if s.useMTDF {
+			result, _ = s.mtdf(state, sr.opts.Depth, 0)
+		} else {
+			result = s.solve(state, sr.opts.Depth, math.Inf(-1), math.Inf(1))
+		}
+		if !s.stopped {
+			s.complete(result)
+		}
+	}
+	stopped := s.stopped && s.ctx != nil && s.ctx.Err() != nil
+	if random && !stopped {
+		s.deadline, s.maxNodes = deadline, maxNodes
+		result = s.randomise(state, result, sr.opts.Random, sr.opts.Rand)
+		stopped = s.stopped && s.ctx != nil && s.ctx.Err() != nil
+	}
+	if stopped {
+		return result, ctx.Err()
+	}
+	return result, nil
+}
+
+// configure sets up the searcher to search with the given options, other than its limits.
+func (s *searcher[G]) configure(opts OptionsOf[G]) {
+	s.opts = opts
+	s.maxDepth = opts.Depth
+	s.maxNodes = opts.Nodes
+	s.aspiration = opts.Aspiration
+	s.pvs = opts.Algorithm == PVS
+	s.useMTDF = opts.Algorithm == MTDf
+	s.multiPV = opts.MultiPV
+	if s.multiPV > 1 {
+		s.aspiration, s.useMTDF = Aspiration{}, false
+	}
+	s.failSoft = s.useMTDF
+	s.onDepth = opts.OnDepth
+	s.onInfo, s.infoInterval = opts.OnInfo, opts.InfoInterval
+	s.tablebase = opts.Tablebase
+}
+
+// randomise replaces the best child in the result with one chosen at random from the children of the root scoring within margin of it.
+func (s *searcher[G]) randomise(state G, result ResultOf[G], margin float64, r *rand.Rand) ResultOf[G] {
+	if result.Depth == 0 || s.rootBest < 0 || isEndingScore(result.Score) {
+		return result
+	}
+	if r == nil {
+		r = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	bound := result.Score - margin
+	children := state.Children()
+	if s.rootBest >= len(children) {
+		return result
+	}
+	nodes := s.nodes
+	candidates := []int{s.rootBest}
+	s.stoppable, s.stopped = true, false
+	for i, child := range children {
+		if i == s.rootBest {
+			continue
+		}
+		// A null window just below the bound shows whether the child reaches it
+		_, score := s.search(child, result.Depth-1, -bound, -math.Nextafter(bound, math.Inf(-1)), 1)
+		if s.stopped {
+			break
+		}
+		if -score >= bound {
+			candidates = append(candidates, i)
+		}
+	}
+	if !s.stopped {
+		// Otherwise out of time or nodes, so keep the best child rather than choosing from only some of the others
+		if choice := candidates[r.Intn(len(candidates))]; choice != s.rootBest {
+			// The null window only bounded the child's score, so search it again with a full window for its principal variation
+			child := children[choice]
+			s.search(child, result.Depth-1, math.Inf(-1), math.Inf(1), 1)
+			result.Best, result.PV = child, []G{child}
+			if !s.stopped {
+				result.PV = s.extendPV(append(result.PV, s.line(child, 1)...), result.Depth)
+			}
+		}
+	}
+	result.Nodes += s.nodes - nodes
+	result.Stats = s.statistics()
+	return result
+}
@@ -0,0 +1,72 @@
+package vulpes
+
+// killerSlots is the number of killer moves remembered at each ply.
+const killerSlots = 2
+
+// Mover is an optional interface a Game may implement to identify the move which led to each state, so that the search can learn which moves are good from the cutoffs they cause, and try them first elsewhere in the tree: the killer heuristic remembers the moves causing the latest cutoffs at each ply, and the history heuristic counts the cutoffs caused by each move throughout the search.
+// The search orders the children of most nodes by calling Evaluate on each of them, but that costs too much just above the depth limit, where there's little left to search below each child, so the children are otherwise left in the order given by the game. Children implementing Mover are ordered there by these heuristics instead.
+type Mover interface {
+	// MoveID returns a small, non-negative number identifying the move made to reach this state, such as the index of the square played in. The same move should have the same ID wherever it's played, and the IDs of the children of a state should be distinct.
+	MoveID() int
+}
+
+// moveOrder holds the killer moves and history scores learned by a search, for games implementing Mover.
+type moveOrder struct {
+	// killers holds the IDs of the moves which last caused a cutoff at each ply, latest first, or -1.
+	killers [][killerSlots]int
+	// history holds the total, over the cutoffs caused by each move ID, of the square of the depth searched.
+	history []float64
+}
+
+// order sorts the children of a node at the given ply by their history scores, keeping the order of the game for equal scores, then moves the killer moves to the front. id returns the move ID of the i'th child.
+func (o *moveOrder) order(id func(i int) int, ply int, scores moveScores) {
+	killers := o.killersAt(ply)
+	for i := range scores {
+		scores[i].moveScore = o.historyScore(id(scores[i].moveIndex))
+	}
+	scores.sortStable()
+	for k := killerSlots - 1; k >= 0; k-- {
+		for i := range scores {
+			if id(scores[i].moveIndex) == killers[k] {
+				scores.promote(i)
+				break
+			}
+		}
+	}
+}
+
+// killersAt returns the killer moves at the given ply.
+func (o *moveOrder) killersAt(ply int) *[killerSlots]int {
+	for len(o.killers) <= ply {
+		o.killers = append(o.killers, [killerSlots]int{-1, -1})
+	}
+	return &o.killers[ply]
+}
+
+// historyScore returns the history score of a move ID.
+func (o *moveOrder) historyScore(id int) float64 {
+	if id < len(o.history) {
+		return o.history[id]
+	}
+	return 0
+}
+
+// cutoff records that the given child caused a cutoff at the given ply, after searching to the given depth.
+func (o *moveOrder) cutoff(child any, depth uint, ply int) {
+	if m, ok := child.(Mover); ok {
+		o.cutoffID(m.MoveID(), depth, ply)
+	}
+}
+
+// cutoffID records that the move with the given ID caused a cutoff at the given ply, after searching to the given depth.
+func (o *moveOrder) cutoffID(id int, depth uint, ply int) {
+	for len(o.history) <= id {
+		o.history = append(o.history, 0)
+	}
+	o.history[id] += float64(depth) * float64(depth)
+	killers := o.killersAt(ply)
+	if killers[0] != id {
+		copy(killers[1:], killers[:killerSlots-1])
+		killers[0] = id
+	}
+}
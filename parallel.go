@@ -0,0 +1,88 @@
+package vulpes
+
+import (
+	"math"
+	"runtime"
+	"sync"
+)
+
+// parallelRoot holds the best of the children of the root searched so far by SolveParallel.
+type parallelRoot struct {
+	sync.Mutex
+	depth uint
+	alpha float64
+	best  Game
+	pv    []Game
+}
+
+// bound returns the score that a child of the root must beat.
+func (p *parallelRoot) bound() float64 {
+	p.Lock()
+	defer p.Unlock()
+	return p.alpha
+}
+
+// searchChild searches a child of the root with the given searcher, keeping it if it's the best so far.
+func (p *parallelRoot) searchChild(s *searcher[Game], child Game) {
+	alpha := p.bound()
+	_, score := s.search(child, p.depth-1, math.Inf(-1), -alpha, 1)
+	score = -score
+	if score <= alpha {
+		return
+	}
+	pv := s.extendPV(append([]Game{child}, s.line(child, 1)...), p.depth)
+	p.Lock()
+	defer p.Unlock()
+	if score > p.alpha {
+		p.alpha, p.best, p.pv = score, child, pv
+	}
+}
+
+// SolveParallel is like Solve, but splits the children of the root between the given number of goroutines (or GOMAXPROCS, if it's not positive). Following Young Brothers Wait, the first child is searched alone, so that the others can be searched with the bound it gives, which is shared and raised as better children are found. It produces the same score as Solve.
+// If the state implements Hasher, the goroutines share a transposition table of DefaultTableSize entries.
+func SolveParallel(state Game, depth uint, workers int) Result {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	table := newSharedTable(state)
+	s := newSearcher[Game](table)
+	if ending, _ := state.Evaluate(); workers == 1 || depth == 0 || ending != UNFINISHED {
+		return s.solve(state, depth, math.Inf(-1), math.Inf(1))
+	}
+	children := state.Children()
+	order := s.orderChildren(children, depth, 0, -1)
+	p := &parallelRoot{depth: depth, alpha: math.Inf(-1)}
+	// The first child gets a full window, so always becomes the best so far
+	p.searchChild(s, children[order[0].moveIndex])
+	jobs := make(chan Game)
+	var wg sync.WaitGroup
+	searchers := make([]*searcher[Game], workers)
+	for i := range searchers {
+		w := s
+		if i > 0 {
+			w = newSearcher[Game](table)
+		}
+		searchers[i] = w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for child := range jobs {
+				p.searchChild(w, child)
+			}
+		}()
+	}
+	for _, moveScore := range order[1:] {
+		jobs <- children[moveScore.moveIndex]
+	}
+	close(jobs)
+	wg.Wait()
+	result := Result{Best: p.best, Score: p.alpha, Depth: depth, PV: p.pv}
+	for _, w := range searchers {
+		result.Nodes += w.nodes
+		if w != s {
+			s.merge(w)
+		}
+	}
+	result.Stats = s.statistics()
+	return result
+}
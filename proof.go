@@ -0,0 +1,195 @@
+package vulpes
+
+// proofInfinity is the proof (or disproof) number of a node which has been disproven (or proven).
+const proofInfinity = 1 << 62
+
+// Proof describes the outcome of a proof-number search.
+type Proof struct {
+	// Outcome is WIN, TIE or LOSS, as proven for the current player in the searched state, or UNFINISHED if the node limit was reached first.
+	Outcome int
+	// Best is a child of the searched state which achieves the outcome, or nil if the game is over, or the outcome wasn't proven.
+	Best Game
+	// Size is the number of nodes in the proof tree: the positions which must be examined to verify the outcome. A tie needs both a proof that the current player can't lose, and a proof that they can't win.
+	Size int
+	// Nodes is the number of nodes created by the search.
+	Nodes int
+}
+
+// proofNode is a node of the tree grown by a proof-number search.
+type proofNode struct {
+	state    Game
+	parent   *proofNode
+	children []*proofNode
+	// or is set when the current player of the node is the one whose outcome is being proven, so it's proven by any one of its children, rather than all of them.
+	or bool
+	// proof and disproof are the number of nodes which must be expanded to prove or disprove the node.
+	proof, disproof uint64
+	// size is the number of nodes in the proof (or disproof) tree of a solved node.
+	size int
+}
+
+// prover holds the state of a single proof-number search.
+type prover struct {
+	// target reports whether an ending, from the perspective of the current player at the root, proves the node.
+	target func(ending int) bool
+	// live is the number of nodes currently held in the tree, and created the number ever created.
+	live, created int
+	maxNodes      int
+}
+
+func (p *prover) newNode(state Game, parent *proofNode) *proofNode {
+	n := &proofNode{state: state, parent: parent, or: parent == nil || !parent.or, proof: 1, disproof: 1, size: 1}
+	p.live++
+	p.created++
+	ending, _ := state.Evaluate()
+	if ending == UNFINISHED {
+		return n
+	}
+	if !n.or {
+		// Convert the ending to the perspective of the current player at the root
+		ending = 2 - ending
+	}
+	if p.target(ending) {
+		n.proof, n.disproof = 0, proofInfinity
+	} else {
+		n.proof, n.disproof = proofInfinity, 0
+	}
+	return n
+}
+
+// solved reports whether the node has been proven or disproven.
+func (n *proofNode) solved() bool {
+	return n.proof == 0 || n.disproof == 0
+}
+
+// mostProving returns the unexpanded node to expand next, by following the children with the smallest proof numbers below OR nodes, and the smallest disproof numbers below AND nodes.
+func mostProving(n *proofNode) *proofNode {
+	for n.children != nil {
+		var next *proofNode
+		for _, child := range n.children {
+			if next == nil || (n.or && child.proof < next.proof) || (!n.or && child.disproof < next.disproof) {
+				next = child
+			}
+		}
+		n = next
+	}
+	return n
+}
+
+// update recalculates the proof and disproof numbers of a node from its children, returning whether they changed.
+func (p *prover) update(n *proofNode) bool {
+	proof, disproof := n.proof, n.disproof
+	var sum uint64
+	var min uint64 = proofInfinity
+	for _, child := range n.children {
+		if n.or {
+			sum += child.disproof
+			if child.proof < min {
+				min = child.proof
+			}
+		} else {
+			sum += child.proof
+			if child.disproof < min {
+				min = child.disproof
+			}
+		}
+		if sum > proofInfinity {
+			sum = proofInfinity
+		}
+	}
+	if n.or {
+		n.proof, n.disproof = min, sum
+	} else {
+		n.proof, n.disproof = sum, min
+	}
+	if n.solved() {
+		p.settle(n)
+	}
+	return n.proof != proof || n.disproof != disproof
+}
+
+// settle records the size of the proof (or disproof) tree of a newly solved node. Except at the root, whose children are needed to find the best move, the children are then released to free memory.
+func (p *prover) settle(n *proofNode) {
+	// A node is solved by any one of its children if it's proven at an OR node, or disproven at an AND node, and otherwise by all of them
+	either := n.or == (n.proof == 0)
+	n.size = 1
+	best := 0
+	for _, child := range n.children {
+		if !child.solved() || (child.proof == 0) != (n.proof == 0) {
+			continue
+		}
+		if !either {
+			n.size += child.size
+		} else if best == 0 || child.size < best {
+			best = child.size
+		}
+	}
+	n.size += best
+	if n.parent != nil {
+		p.release(n)
+	}
+}
+
+// release removes the children of a node from the tree.
+func (p *prover) release(n *proofNode) {
+	for _, child := range n.children {
+		p.release(child)
+		p.live--
+	}
+	n.children = nil
+}
+
+// prove runs a proof-number search from the root, until it's solved or the node limit is reached.
+func (p *prover) prove(root *proofNode) {
+	for !root.solved() && (p.maxNodes <= 0 || p.live < p.maxNodes) {
+		n := mostProving(root)
+		children := n.state.Children()
+		n.children = make([]*proofNode, len(children))
+		for i, child := range children {
+			n.children[i] = p.newNode(child, n)
+		}
+		for ; n != nil && p.update(n); n = n.parent {
+		}
+	}
+}
+
+// Prove takes a starting node for the game, and proves whether the current player wins, ties or loses with perfect play, by proof-number search. Unlike SolveGame, it makes no use of heuristics, so it only searches as far as is needed to prove the outcome, expanding the nodes which are the easiest to prove or disprove first.
+// The search holds at most maxNodes nodes in memory at once (or is unlimited, if it's not positive), giving up with UNFINISHED if it can't prove the outcome within that. A win is proven first, then failing that, a tie.
+func Prove(state Game, maxNodes int) Proof {
+	if ending, _ := state.Evaluate(); ending != UNFINISHED {
+		return Proof{Outcome: ending, Size: 1, Nodes: 1}
+	}
+	p := &prover{target: func(ending int) bool { return ending == WIN }, maxNodes: maxNodes}
+	win := p.newNode(state, nil)
+	p.prove(win)
+	if win.proof == 0 {
+		return Proof{Outcome: WIN, Best: win.provingChild(), Size: win.size, Nodes: p.created}
+	}
+	if win.disproof != 0 {
+		return Proof{Outcome: UNFINISHED, Nodes: p.created}
+	}
+	// The current player can't win, so try to prove that they can at least tie
+	p.release(win)
+	p.live--
+	p.target = func(ending int) bool { return ending != LOSS }
+	tie := p.newNode(state, nil)
+	p.prove(tie)
+	switch {
+	case tie.proof == 0:
+		return Proof{Outcome: TIE, Best: tie.provingChild(), Size: win.size + tie.size, Nodes: p.created}
+	case tie.disproof == 0:
+		return Proof{Outcome: LOSS, Best: tie.children[0].state, Size: tie.size, Nodes: p.created}
+	}
+	return Proof{Outcome: UNFINISHED, Nodes: p.created}
+}
+
+// provingChild returns the state of the child with the smallest proof tree among those proving a proven root.
+func (n *proofNode) provingChild() Game {
+	var best *proofNode
+	for _, child := range n.children {
+		if child.proof == 0 && (best == nil || child.size < best.size) {
+			best = child
+		}
+	}
+	return best.state
+}
@@ -0,0 +1,107 @@
+package vulpes
+
+import (
+	"math"
+)
+
+// maxExtension limits how many plies the search may be extended past its depth by Tactical games.
+const maxExtension = 64
+
+// Tactical is an optional interface a Game may implement to extend the search past its depth limit through noisy positions, where the heuristic from Evaluate can't be trusted, such as when a player has a win pending.
+type Tactical = TacticalOf[Game]
+
+// TacticalOf is the generic form of Tactical, for a GameOf[G].
+type TacticalOf[G any] interface {
+	// Quiet reports whether the heuristic from Evaluate can be trusted for this state.
+	Quiet() bool
+	// TacticalChildren returns the children to search from a state which isn't quiet, such as immediate wins, or the moves forced to block them. Only these children are searched, so they should include every reasonable move, and the score of an extended state is never treated as exact. If there are none, the heuristic is used anyway.
+	TacticalChildren() []G
+}
+
+// quiesce scores an unfinished state at the depth limit of the search. If it's Tactical and not quiet, the search is extended through its tactical children until reaching quiet states.
+func (s *searcher[G]) quiesce(state G, heuristic float64, alpha, beta float64, ply int, extension int) float64 {
+	t, ok := any(state).(TacticalOf[G])
+	if !ok || extension >= maxExtension || t.Quiet() {
+		s.horizon = true
+		s.stats.Leaves++
+		return heuristic
+	}
+	// Only the tactical children are searched, so the score is never exact
+	s.horizon = true
+	if g, ok := any(state).(TacticalMoverOf[G]); ok && ply > 0 {
+		return s.quiesceMoves(state, g, heuristic, alpha, beta, ply, extension)
+	}
+	children := t.TacticalChildren()
+	if len(children) == 0 {
+		s.stats.Leaves++
+		return heuristic
+	}
+	bestScore := math.Inf(-1)
+	for _, child := range children {
+		score := -s.quiescent(child, -beta, -alpha, ply+1, extension+1)
+		if s.stopped {
+			return alpha
+		}
+		bestScore = math.Max(bestScore, score)
+		if score > alpha {
+			alpha = score
+			s.pv[ply] = append(append(s.pv[ply][:0], child), s.line(child, ply+1)...)
+			if beta <= alpha {
+				if s.failSoft {
+					return bestScore
+				}
+				return beta
+			}
+		}
+	}
+	if s.failSoft {
+		return bestScore
+	}
+	return alpha
+}
+
+// quiesceMoves is like quiesce, once a state's been found to need extending, but plays the tactical moves of a TacticalMover, given as both the state and its TacticalMoverOf view, in place. The caller has already set the horizon.
+func (s *searcher[G]) quiesceMoves(state G, g TacticalMoverOf[G], heuristic float64, alpha, beta float64, ply int, extension int) float64 {
+	moves := s.moveBuf(ply, g.TacticalMoves)
+	if len(moves) == 0 {
+		s.stats.Leaves++
+		return heuristic
+	}
+	bestScore := math.Inf(-1)
+	for _, m := range moves {
+		g.Play(m)
+		score := -s.quiescent(state, -beta, -alpha, ply+1, extension+1)
+		g.Undo(m)
+		if s.stopped {
+			return alpha
+		}
+		bestScore = math.Max(bestScore, score)
+		if score > alpha {
+			alpha = score
+			s.playedPV(m, ply)
+			if beta <= alpha {
+				if s.failSoft {
+					return bestScore
+				}
+				return beta
+			}
+		}
+	}
+	if s.failSoft {
+		return bestScore
+	}
+	return alpha
+}
+
+// quiescent searches a child of a state being quiesced.
+func (s *searcher[G]) quiescent(state G, alpha, beta float64, ply int, extension int) float64 {
+	if !s.enter(ply) {
+		return alpha
+	}
+	ending, heuristic := state.Evaluate()
+	if ending != UNFINISHED {
+		s.stats.Leaves++
+		return endingScore(ending, ply)
+	}
+	return s.quiesce(state, heuristic, alpha, beta, ply, extension)
+}
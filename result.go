@@ -0,0 +1,58 @@
+package vulpes
+
+import (
+	"context"
+	"time"
+)
+
+// Result describes the outcome of a search.
+type Result = ResultOf[Game]
+
+// ResultOf is the generic form of Result, describing the outcome of a search of a GameOf[G].
+type ResultOf[G any] struct {
+	// Best is the best child of the searched state, or the state itself if the game is over.
+	Best G
+	// Score is the score of Best, from the perspective of the current player in the searched state.
+	Score float64
+	// Depth is the depth that the search was completed to.
+	Depth uint
+	// PV is the principal variation: the line of play expected to follow the searched state, starting with Best. It may be cut short of Depth where the rest of the line was taken from the transposition table, or the game ended.
+	PV []G
+	// Nodes is the number of nodes visited by the search, including any shallower searches leading up to it.
+	Nodes uint64
+	// Lines holds the best children of the root, best first, each with its exact score and principal variation, when searching with Options.MultiPV greater than 1. The first line is Best. It's nil if the game is over, or the depth is 0.
+	Lines []LineOf[G]
+	// Stats describes the work done by the search.
+	Stats Stats
+}
+
+// Solve takes a starting node for the game, and returns the result of searching it to the specified depth.
+// If the state implements Hasher, a transposition table of DefaultTableSize entries is used.
+func Solve(state Game, depth uint) Result {
+	result, _ := NewSearcher(Options{Depth: depth}).Search(context.Background(), state)
+	return result
+}
+
+// SolveOf is the generic form of Solve, for a GameOf[G].
+func SolveOf[G GameOf[G]](state G, depth uint) ResultOf[G] {
+	result, _ := NewSearcherOf(OptionsOf[G]{Depth: depth}).Search(context.Background(), state)
+	return result
+}
+
+// SolveContext is like Solve, but gives up once ctx is done, returning the best child found so far (out of those completely searched, or else the first to be searched), and ctx.Err().
+func SolveContext(ctx context.Context, state Game, depth uint) (Result, error) {
+	return NewSearcher(Options{Depth: depth}).Search(ctx, state)
+}
+
+// SolveTimed takes a starting node for the game, and returns the result of searching as deep as possible within the given time limit.
+func SolveTimed(state Game, limit time.Duration) Result {
+	return SolveDeadline(state, time.Now().Add(limit))
+}
+
+// SolveDeadline is like SolveTimed, but searches until the given deadline.
+// The search is deepened one ply at a time, with each search trying the best moves found by the previous one first, and the result of the last completed search is returned. A search to depth 1 is always completed, even if the deadline has already passed.
+func SolveDeadline(state Game, deadline time.Time) Result {
+	s := newSearcher[Game](newTable(state))
+	s.deadline = deadline
+	return s.deepen(state)
+}
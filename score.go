@@ -0,0 +1,60 @@
+package vulpes
+
+import (
+	"math"
+)
+
+// WinScore is the score of a game won by the current player. Wins are scored as WinScore less the number of plies until the game ends (and losses as the negation), so that quicker wins and slower losses are preferred. Heuristic scores must lie well within ±WinScore.
+const WinScore = 1 << 50
+
+// maxPlies bounds the distance to the end of the game that can be encoded in a score.
+const maxPlies = 1 << 20
+
+// endingScore returns the score of a finished game, ply plies from the root of the search.
+func endingScore(ending int, ply int) float64 {
+	switch ending {
+	case LOSS:
+		return -WinScore + float64(ply)
+	case WIN:
+		return WinScore - float64(ply)
+	}
+	return 0
+}
+
+// isEndingScore reports whether the score is that of a won or lost game.
+func isEndingScore(score float64) bool {
+	return math.Abs(score) > WinScore-maxPlies && math.Abs(score) <= WinScore
+}
+
+// DecodeScore returns the ending that a score from a search guarantees, and the number of plies until that ending. For a forced win, it returns WIN and the number of plies to win; for a forced loss, LOSS and the number of plies until the loss. Otherwise it returns UNFINISHED, 0.
+func DecodeScore(score float64) (ending int, plies int) {
+	if !isEndingScore(score) {
+		return UNFINISHED, 0
+	}
+	if score > 0 {
+		return WIN, int(WinScore - score)
+	}
+	return LOSS, int(WinScore + score)
+}
+
+// toTable converts a score relative to the root of the search into one relative to the node at the given ply, for storing in the table.
+func toTable(score float64, ply int) float64 {
+	if !isEndingScore(score) {
+		return score
+	}
+	if score > 0 {
+		return score + float64(ply)
+	}
+	return score - float64(ply)
+}
+
+// fromTable is the inverse of toTable.
+func fromTable(score float64, ply int) float64 {
+	if !isEndingScore(score) {
+		return score
+	}
+	if score > 0 {
+		return score - float64(ply)
+	}
+	return score + float64(ply)
+}
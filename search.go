@@ -0,0 +1,468 @@
+package vulpes
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// exactDepth is stored in the table for results which didn't depend on the search depth, as no heuristic scores were used.
+const exactDepth = math.MaxUint32
+
+// stopInterval is the number of nodes searched between checks of whether the search should stop.
+const stopInterval = 1 << 10
+
+type moveScore struct {
+	moveIndex int
+	moveScore float64
+}
+
+type moveScores []moveScore
+
+func (s moveScores) Len() int           { return len(s) }
+func (s moveScores) Less(i, j int) bool { return s[i].moveScore > s[j].moveScore }
+func (s moveScores) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// sortStable sorts the moves by insertion, keeping the order of moves with equal scores, and without the allocations of the sort package.
+func (s moveScores) sortStable() {
+	for i := 1; i < len(s); i++ {
+		for j := i; j > 0 && s.Less(j, j-1); j-- {
+			s.Swap(j, j-1)
+		}
+	}
+}
+
+// promote moves the i'th move to the front, keeping the order of the rest.
+func (s moveScores) promote(i int) {
+	m := s[i]
+	copy(s[1:i+1], s[:i])
+	s[0] = m
+}
+
+// searcher holds the state shared by every node of a single search of states of type G.
+type searcher[G GameOf[G]] struct {
+	table *Table
+	// tablebase scores the positions it holds below the root, if non-nil.
+	tablebase *Tablebase
+	// ctx cancels a stoppable search, if non-nil.
+	ctx context.Context
+	// abort stops a stoppable search once set to non-zero by another goroutine, if non-nil.
+	abort *int32
+	// deadline is the time at which a stoppable search gives up, if non-zero.
+	deadline time.Time
+	// stoppable is set when the search may be abandoned part-way through.
+	stoppable bool
+	// stopped is set once the search has been abandoned, after which all results are meaningless.
+	stopped bool
+	// horizon is set when a heuristic score has been used in the current subtree.
+	horizon bool
+	// chanced is set once a chance node has been searched.
+	chanced bool
+	// mixed is set when a chance node in the current subtree has averaged the score of an ended game with other scores. Unlike the score of an ended game itself, the average can't be corrected for the ply it was found at, so it can't be stored in the table.
+	mixed bool
+	// maxDepth limits the depth of iterative deepening, if non-zero.
+	maxDepth uint
+	// maxNodes is the number of nodes after which a stoppable search gives up, if non-zero.
+	maxNodes uint64
+	// aspiration configures the search windows used by iterative deepening.
+	aspiration Aspiration
+	// pvs enables Principal Variation Search, which searches all but the first child of each node with a null window.
+	pvs bool
+	// useMTDF makes iterative deepening search each depth with MTD(f), rather than aspiration windows.
+	useMTDF bool
+	// onDepth is called with the result of each depth completed by iterative deepening, if non-nil.
+	onDepth func(ResultOf[G])
+	// onInfo is called with the progress of the search after each completed depth, and every infoInterval during a depth, if non-nil.
+	onInfo       func(InfoOf[G])
+	infoInterval time.Duration
+	// lastInfo is the time progress was last reported.
+	lastInfo time.Time
+	// depth is the depth of the search in progress.
+	depth uint
+	// rootScore is the score of the best child of the root found so far by the search in progress.
+	rootScore float64
+	// last is the result of the last completed depth, once lasted is set.
+	last   ResultOf[G]
+	lasted bool
+	// failSoft makes each node return its best score, even when it lies outside of the search window, rather than clamping it to the window.
+	failSoft bool
+	// rotate rotates the order in which the children of the root are searched, after the best move from the previous search, so that parallel searches explore the tree differently.
+	rotate int
+	// rootBest is the index of the best child of the root from the previous search, or -1.
+	rootBest int
+	// multiPV is the number of the best children of the root to find, if greater than 1, and rootLines holds the indices of those found by the previous search, best first.
+	multiPV   int
+	rootLines []int
+	// opts enables the selective search techniques.
+	opts OptionsOf[G]
+	// nullPly is the ply of the state following the latest null move being searched, from which another null move isn't tried.
+	nullPly int
+	// moves holds the killer moves and history scores learned so far, for games implementing Mover.
+	moves moveOrder
+	// pv holds the principal variation found below each ply
+	pv [][]G
+	// pvMoves holds the moves starting the principal variation below each ply played in place, which pv then continues.
+	pvMoves [][]Move
+	// moveBufs and scoreBufs hold the moves of each ply searched by playMoves, and their order.
+	moveBufs  [][]Move
+	scoreBufs []moveScores
+	nodes     uint64
+	// stats counts the work done, other than the nodes, plus that of any helpers merged in.
+	stats Stats
+	// start is the time the search started.
+	start time.Time
+}
+
+func newSearcher[G GameOf[G]](table *Table) *searcher[G] {
+	now := time.Now()
+	return &searcher[G]{table: table, rootBest: -1, opts: OptionsOf[G]{}.withDefaults(), start: now, lastInfo: now}
+}
+
+// staticScore scores a state ply plies from the root without searching any further.
+func staticScore(ending int, heuristic float64, ply int) float64 {
+	if ending == UNFINISHED {
+		return heuristic
+	}
+	return endingScore(ending, ply)
+}
+
+// poll counts a searched node, periodically checking whether the search should be abandoned.
+func (s *searcher[G]) poll() {
+	s.nodes++
+	if s.stoppable && s.maxNodes > 0 && s.nodes >= s.maxNodes {
+		s.stopped = true
+	}
+	if s.nodes%stopInterval != 0 {
+		return
+	}
+	if s.onInfo != nil && s.infoInterval > 0 && time.Since(s.lastInfo) >= s.infoInterval {
+		s.info()
+	}
+	if !s.stoppable {
+		return
+	}
+	if !s.deadline.IsZero() && time.Now().After(s.deadline) {
+		s.stopped = true
+	}
+	if s.ctx != nil && s.ctx.Err() != nil {
+		s.stopped = true
+	}
+	if s.abort != nil && atomic.LoadInt32(s.abort) != 0 {
+		s.stopped = true
+	}
+}
+
+// search is the Negamax recursion behind Search. ply is the distance from the root of the search.
+func (s *searcher[G]) search(state G, depth uint, alpha, beta float64, ply int) (G, float64) {
+	horizon, mixed := s.horizon, s.mixed
+	s.horizon, s.mixed = false, false
+	best, score := s.negamax(state, depth, alpha, beta, ply)
+	s.horizon = s.horizon || horizon
+	s.mixed = s.mixed || mixed
+	return best, score
+}
+
+// enter starts the search of a node at the given ply, returning false if the search has been stopped.
+func (s *searcher[G]) enter(ply int) bool {
+	for len(s.pv) <= ply+1 {
+		s.pv = append(s.pv, nil)
+		s.pvMoves = append(s.pvMoves, nil)
+	}
+	s.pv[ply] = s.pv[ply][:0]
+	s.pvMoves[ply] = s.pvMoves[ply][:0]
+	s.poll()
+	return !s.stopped
+}
+
+func (s *searcher[G]) negamax(state G, depth uint, alpha, beta float64, ply int) (G, float64) {
+	if !s.enter(ply) {
+		return state, alpha
+	}
+	ending, heuristic := state.Evaluate()
+	if ending != UNFINISHED {
+		s.stats.Leaves++
+		return state, endingScore(ending, ply)
+	}
+	if s.tablebase != nil && ply > 0 {
+		if h, ok := any(state).(Hasher); ok {
+			if ending, plies, found := s.tablebase.probe(h.Hash()); found {
+				s.stats.Leaves++
+				return state, tablebaseScore(ending, plies, ply)
+			}
+		}
+	}
+	if c, ok := any(state).(ChanceNodeOf[G]); ok {
+		// There's no move to make from a chance node, so it's its own best child
+		if outcomes := c.Outcomes(); outcomes != nil {
+			return state, s.chance(outcomes, depth, alpha, beta, ply)
+		}
+	}
+	if depth == 0 {
+		return state, s.quiesce(state, heuristic, alpha, beta, ply, 0)
+	}
+	var hash uint64
+	var hashed bool
+	bestIndex := -1
+	if s.table != nil {
+		if h, ok := any(state).(Hasher); ok {
+			hash, hashed = h.Hash(), true
+			s.stats.TableProbes++
+			if entry, found := s.table.probe(hash); found {
+				s.stats.TableHits++
+				bestIndex = int(entry.best)
+				entry.score = fromTable(entry.score, ply)
+				// The root always needs a best child, so only take cutoffs further down the tree
+				if ply > 0 && uint(entry.depth) >= depth {
+					cutoff := true
+					score := entry.score
+					switch {
+					case entry.bound == exactBound:
+						if !s.failSoft {
+							score = math.Min(math.Max(score, alpha), beta)
+						}
+					case entry.bound == lowerBound && entry.score >= beta:
+						if !s.failSoft {
+							score = beta
+						}
+					case entry.bound == upperBound && entry.score <= alpha:
+						if !s.failSoft {
+							score = alpha
+						}
+					default:
+						cutoff = false
+					}
+					if cutoff {
+						s.stats.TableCutoffs++
+						if entry.depth != exactDepth {
+							s.horizon = true
+						}
+						return state, score
+					}
+				}
+			}
+		}
+	}
+	if cutoff, score := s.nullMove(state, heuristic, depth, beta, ply); cutoff {
+		return state, score
+	}
+	if g, ok := any(state).(MoveGameOf[G]); ok && ply > 0 {
+		return state, s.playMoves(state, g, depth, alpha, beta, ply, hashed, hash, bestIndex)
+	}
+	if ply == 0 && s.rootBest >= 0 {
+		bestIndex = s.rootBest
+	}
+	children := state.Children()
+	moveScores := s.orderChildren(children, depth, ply, bestIndex)
+	if ply == 0 && s.rotate > 0 && len(moveScores) > 2 {
+		start := 0
+		if bestIndex >= 0 {
+			start = 1
+		}
+		rest := moveScores[start:]
+		rotated := append(append(moveScores[:0:0], rest[s.rotate%len(rest):]...), rest[:s.rotate%len(rest)]...)
+		copy(rest, rotated)
+	}
+	var tmpScore float64
+	origAlpha := alpha
+	bestScore := math.Inf(-1)
+	// bestChild is the best child so far, once found is set
+	var bestChild G
+	var found bool
+	for i, moveScore := range moveScores {
+		child := children[moveScore.moveIndex]
+		tmpScore = s.searchChild(child, i, depth, alpha, beta, ply)
+		if s.stopped {
+			if !found {
+				bestChild = children[moveScores[0].moveIndex]
+			}
+			// Return the best of the fully searched children, which the root makes use of
+			return bestChild, alpha
+		}
+		bestScore = math.Max(bestScore, tmpScore)
+		if tmpScore > alpha {
+			alpha = tmpScore
+			bestChild, found = child, true
+			bestIndex = moveScore.moveIndex
+			s.pv[ply] = append(append(s.pv[ply][:0], child), s.line(child, ply+1)...)
+			if ply == 0 {
+				s.rootBest, s.rootScore = bestIndex, tmpScore
+			}
+			if beta <= alpha {
+				s.moves.cutoff(child, depth, ply)
+				s.stats.cutoff(depth, i)
+				s.store(hashed, hash, bestScore, depth, ply, bestIndex, lowerBound)
+				if s.failSoft {
+					return bestChild, bestScore
+				}
+				return bestChild, beta
+			}
+		}
+		if !found {
+			// Take the first child, in case all the children are terrible.
+			bestChild, found = child, true
+		}
+	}
+	if bestScore <= origAlpha {
+		s.store(hashed, hash, bestScore, depth, ply, bestIndex, upperBound)
+	} else {
+		s.store(hashed, hash, bestScore, depth, ply, bestIndex, exactBound)
+	}
+	if !found {
+		// No possible moves, so return the current state.
+		bestChild = state
+	}
+	if s.failSoft {
+		return bestChild, bestScore
+	}
+	return bestChild, alpha
+}
+
+// orderChildren returns the order to search the children of a node in, trying the best move from a previous search first (if bestIndex isn't -1), then the rest by their heuristic scores, or just above the depth limit, by the killer and history heuristics if they implement Mover.
+func (s *searcher[G]) orderChildren(children []G, depth uint, ply int, bestIndex int) moveScores {
+	moveScores := make(moveScores, len(children))
+	for i := range children {
+		moveScores[i] = moveScore{i, 0.0}
+	}
+	if depth > 1 {
+		// Pre-sort the possible moves by their score to speed up the pruning
+		for i, child := range children {
+			ending, heuristic := child.Evaluate()
+			moveScores[i].moveScore = -staticScore(ending, heuristic, ply+1)
+		}
+		sort.Sort(moveScores)
+	} else if len(children) > 0 {
+		if _, ok := any(children[0]).(Mover); ok {
+			// The children are left unsorted just above the depth limit, where evaluating them all up front costs too much, but the moves which caused cutoffs elsewhere are cheap to try first
+			s.moves.order(func(i int) int { return any(children[i]).(Mover).MoveID() }, ply, moveScores)
+		}
+	}
+	if bestIndex >= 0 && bestIndex < len(children) {
+		// Try the best move from a previous search first
+		for i := range moveScores {
+			if moveScores[i].moveIndex == bestIndex {
+				moveScores.promote(i)
+				break
+			}
+		}
+	}
+	return moveScores
+}
+
+// searchChild returns the score of the i'th child to be searched of a node, from the perspective of the node.
+func (s *searcher[G]) searchChild(child G, i int, depth uint, alpha, beta float64, ply int) float64 {
+	if s.reduce(child, i, depth, alpha) {
+		// Search late moves to a reduced depth with a null window, only searching them fully if they might be better than the best so far
+		_, score := s.search(child, depth-1-s.opts.LateMoveReduction, -math.Nextafter(alpha, beta), -alpha, ply+1)
+		if s.stopped || -score <= alpha {
+			return -score
+		}
+	}
+	if s.pvs && i > 0 {
+		// Try to prove that the child is no better than the best so far with a null window, only searching again if that fails
+		_, score := s.search(child, depth-1, -math.Nextafter(alpha, beta), -alpha, ply+1)
+		if s.stopped || -score <= alpha || -score >= beta {
+			return -score
+		}
+	}
+	_, score := s.search(child, depth-1, -beta, -alpha, ply+1)
+	return -score
+}
+
+func (s *searcher[G]) store(hashed bool, hash uint64, score float64, depth uint, ply int, best int, b bound) {
+	if !hashed || s.mixed {
+		return
+	}
+	entry := tableEntry{hash: hash, score: toTable(score, ply), depth: uint32(depth), best: int32(best), bound: b}
+	if !s.horizon {
+		// No heuristics were involved, so the result holds at any depth
+		entry.depth = exactDepth
+	}
+	s.table.store(entry)
+}
+
+// solve searches state to the given depth, collecting the result.
+func (s *searcher[G]) solve(state G, depth uint, alpha, beta float64) ResultOf[G] {
+	if s.multiPV > 1 && depth > 0 {
+		if ending, _ := state.Evaluate(); ending == UNFINISHED {
+			return s.solveLines(state, depth)
+		}
+	}
+	s.depth = depth
+	best, score := s.search(state, depth, alpha, beta, 0)
+	result := ResultOf[G]{Best: best, Score: score, Depth: depth, Nodes: s.nodes, Stats: s.statistics()}
+	if len(s.pv) > 0 && len(s.pv[0]) > 0 {
+		result.PV = s.extendPV(append([]G(nil), s.pv[0]...), depth)
+	}
+	return result
+}
+
+// extendPV follows the best moves stored in the table for exactly scored positions, to continue a principal variation which was cut short by a table lookup.
+func (s *searcher[G]) extendPV(pv []G, depth uint) []G {
+	if s.table == nil {
+		return pv
+	}
+	for uint(len(pv)) < depth {
+		last := pv[len(pv)-1]
+		h, ok := any(last).(Hasher)
+		if !ok {
+			break
+		}
+		entry, found := s.table.probe(h.Hash())
+		if !found || entry.bound != exactBound || entry.best < 0 {
+			break
+		}
+		if ending, _ := last.Evaluate(); ending != UNFINISHED {
+			break
+		}
+		children := last.Children()
+		if int(entry.best) >= len(children) {
+			break
+		}
+		pv = append(pv, children[entry.best])
+	}
+	return pv
+}
+
+// deepen runs successively deeper searches from state until the search is stopped, the maximum depth is reached, or the game has been searched to the end. It returns the result of the last completed search.
+func (s *searcher[G]) deepen(state G) ResultOf[G] {
+	var result ResultOf[G]
+	// rootBest is the index of the best child of the root in the result
+	rootBest := -1
+	// scores holds the score found at each depth
+	var scores []float64
+	for depth := uint(1); s.maxDepth == 0 || depth <= s.maxDepth; depth++ {
+		// Always complete the first search, so that there's a move to make
+		s.stoppable = depth > 1
+		var next ResultOf[G]
+		// Heuristics often favour whoever moved last, so the score from two plies shallower, with the same player moving last, is the better guess
+		var guess float64
+		if depth > 2 {
+			guess = scores[depth-3]
+		} else if depth > 1 {
+			guess = scores[depth-2]
+		}
+		if s.useMTDF {
+			s.horizon = false
+			next, _ = s.mtdf(state, depth, guess)
+		} else if depth > 1 {
+			next = s.aspire(state, depth, guess)
+		} else {
+			s.horizon = false
+			next = s.solve(state, depth, math.Inf(-1), math.Inf(1))
+		}
+		if s.stopped {
+			break
+		}
+		result, rootBest = next, s.rootBest
+		scores = append(scores, result.Score)
+		s.complete(result)
+		if !s.horizon || (!s.deadline.IsZero() && time.Now().After(s.deadline)) {
+			break
+		}
+	}
+	s.rootBest = rootBest
+	result.Stats = s.statistics()
+	return result
+}
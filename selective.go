@@ -0,0 +1,72 @@
+package vulpes
+
+import (
+	"math"
+)
+
+const (
+	// DefaultNullMoveReduction is the number of plies the search after a null move is reduced by, if Options.NullMoveReduction is 0.
+	DefaultNullMoveReduction = 2
+	// DefaultLateMoveStart is the number of children searched to the full depth before late move reductions start, if Options.LateMoveStart is 0.
+	DefaultLateMoveStart = 3
+	// DefaultLateMoveReduction is the number of plies late moves are reduced by, if Options.LateMoveReduction is 0.
+	DefaultLateMoveReduction = 1
+)
+
+// NullMover is an optional interface a Game may implement when passing the turn is meaningful, to allow null-move pruning: if the current player would still be doing well enough to cause a cutoff after letting their opponent move twice in a row, then a real move is assumed to do at least as well. This is unsound in zugzwang, where every move makes things worse for the player making it, so passing should be refused wherever that's likely.
+type NullMover = NullMoverOf[Game]
+
+// NullMoverOf is the generic form of NullMover, for a GameOf[G].
+type NullMoverOf[G any] interface {
+	// NullMove returns the state after the current player passes the turn to their opponent, and true, or false if passing isn't safe from this state, such as when a player has a win pending.
+	NullMove() (G, bool)
+}
+
+// nullState returns the state after the current player of state passes, if it implements NullMoverOf[G] and passing is safe.
+func nullState[G any](state G) (G, bool) {
+	if n, ok := any(state).(NullMoverOf[G]); ok {
+		return n.NullMove()
+	}
+	var null G
+	return null, false
+}
+
+// nullMove tries null-move pruning at a node with the given heuristic score, returning whether the node can be cut off, and the score to cut it off with.
+func (s *searcher[G]) nullMove(state G, heuristic float64, depth uint, beta float64, ply int) (bool, float64) {
+	r := s.opts.NullMoveReduction
+	// Passing twice in a row would just search the same state again at a lower depth
+	if !s.opts.NullMove || ply == 0 || ply == s.nullPly || depth <= r || heuristic < beta || math.IsInf(beta, 1) || isEndingScore(beta) {
+		return false, 0
+	}
+	null, ok := nullState(state)
+	if !ok {
+		return false, 0
+	}
+	nullPly := s.nullPly
+	s.nullPly = ply + 1
+	_, score := s.search(null, depth-1-r, -beta, -math.Nextafter(beta, math.Inf(-1)), ply+1)
+	s.nullPly = nullPly
+	score = -score
+	if s.stopped || score < beta {
+		return false, 0
+	}
+	// The cutoff rests on the assumption that passing is no better than moving, so it's never exact
+	s.horizon = true
+	if !s.failSoft || isEndingScore(score) {
+		// A forced ending found after a pass needn't be reachable by moving
+		return true, beta
+	}
+	return true, score
+}
+
+// reduce reports whether the i'th child to be searched of a node should be searched to a reduced depth first.
+func (s *searcher[G]) reduce(child G, i int, depth uint, alpha float64) bool {
+	if !s.opts.LateMoveReductions || i < s.opts.LateMoveStart || depth <= s.opts.LateMoveReduction+1 || math.IsInf(alpha, -1) {
+		return false
+	}
+	// Moves creating threats are never reduced
+	if t, ok := any(child).(TacticalOf[G]); ok && !t.Quiet() {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package vulpes
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// SolveLazySMP is like Solve, but searches with Lazy SMP: the given number of goroutines (or GOMAXPROCS, if it's not positive) each search the whole tree by iterative deepening, sharing their results through a transposition table. Helper goroutines alternate between searching one ply deeper than the main one, and differ in the order they search the children of the root, so that they tend to fill the table ahead of the main search. The result comes from the main search, once it completes the given depth.
+// If the state implements Hasher, the goroutines share a transposition table of DefaultTableSize entries; otherwise there is nothing to be gained from the helpers.
+func SolveLazySMP(state Game, depth uint, threads int) Result {
+	s := newSearcher[Game](newSharedTable(state))
+	if depth == 0 {
+		return s.solve(state, depth, math.Inf(-1), math.Inf(1))
+	}
+	s.maxDepth = depth
+	return s.lazySMP(state, threads)
+}
+
+// lazySMP deepens the search from state as the main search of Lazy SMP, with the given number of goroutines (or GOMAXPROCS, if it's not positive). The helpers share its table and options.
+func (s *searcher[G]) lazySMP(state G, threads int) ResultOf[G] {
+	if threads <= 0 {
+		threads = runtime.GOMAXPROCS(0)
+	}
+	var abort int32
+	var wg sync.WaitGroup
+	helpers := make([]*searcher[G], threads-1)
+	for i := range helpers {
+		h := newSearcher[G](s.table)
+		h.configure(s.opts)
+		if s.maxDepth > 0 {
+			h.maxDepth = s.maxDepth + uint(i%2)
+		}
+		h.maxNodes, h.onDepth, h.onInfo = 0, nil, nil
+		h.rotate = i + 1
+		h.abort = &abort
+		helpers[i] = h
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.deepen(state)
+		}()
+	}
+	result := s.deepen(state)
+	atomic.StoreInt32(&abort, 1)
+	wg.Wait()
+	for _, h := range helpers {
+		result.Nodes += h.nodes
+		s.merge(h)
+	}
+	result.Stats = s.statistics()
+	return result
+}
@@ -0,0 +1,90 @@
+package vulpes
+
+import (
+	"time"
+)
+
+// Stats describes the work done by a search.
+type Stats struct {
+	// Nodes is the number of nodes visited, including those of any search abandoned part-way through.
+	Nodes uint64
+	// Leaves is the number of nodes scored without searching any further: by their heuristic, as ended games, or from the tablebase.
+	Leaves uint64
+	// Cutoffs holds the number of beta cutoffs at each depth, indexed by the depth left to search below the node.
+	Cutoffs []uint64
+	// FirstMoveCutoffs is the number of cutoffs caused by the first child searched, which measures how well the children are ordered.
+	FirstMoveCutoffs uint64
+	// TableProbes is the number of lookups in the transposition table, TableHits the number finding an entry, and TableCutoffs the number whose entry settled the score without searching the node.
+	TableProbes, TableHits, TableCutoffs uint64
+	// Elapsed is the time taken by the search.
+	Elapsed time.Duration
+}
+
+// TotalCutoffs returns the number of beta cutoffs at every depth.
+func (st Stats) TotalCutoffs() uint64 {
+	var total uint64
+	for _, n := range st.Cutoffs {
+		total += n
+	}
+	return total
+}
+
+// FirstMoveCutoffRate returns the fraction of beta cutoffs caused by the first child searched, or 0 if there were none.
+func (st Stats) FirstMoveCutoffRate() float64 {
+	total := st.TotalCutoffs()
+	if total == 0 {
+		return 0
+	}
+	return float64(st.FirstMoveCutoffs) / float64(total)
+}
+
+// NodesPerSecond returns the number of nodes visited per second, or 0 if no time has elapsed.
+func (st Stats) NodesPerSecond() float64 {
+	if st.Elapsed <= 0 {
+		return 0
+	}
+	return float64(st.Nodes) / st.Elapsed.Seconds()
+}
+
+// add adds the counts of another search to the stats, leaving Elapsed alone.
+func (st *Stats) add(other Stats) {
+	st.Nodes += other.Nodes
+	st.Leaves += other.Leaves
+	for len(st.Cutoffs) < len(other.Cutoffs) {
+		st.Cutoffs = append(st.Cutoffs, 0)
+	}
+	for depth, n := range other.Cutoffs {
+		st.Cutoffs[depth] += n
+	}
+	st.FirstMoveCutoffs += other.FirstMoveCutoffs
+	st.TableProbes += other.TableProbes
+	st.TableHits += other.TableHits
+	st.TableCutoffs += other.TableCutoffs
+}
+
+// cutoff counts a beta cutoff at the given depth, caused by the i'th child searched.
+func (st *Stats) cutoff(depth uint, i int) {
+	for uint(len(st.Cutoffs)) <= depth {
+		st.Cutoffs = append(st.Cutoffs, 0)
+	}
+	st.Cutoffs[depth]++
+	if i == 0 {
+		st.FirstMoveCutoffs++
+	}
+}
+
+// statistics returns the stats of the search so far.
+func (s *searcher[G]) statistics() Stats {
+	st := s.stats
+	st.Nodes += s.nodes
+	st.Cutoffs = append([]uint64(nil), s.stats.Cutoffs...)
+	st.Elapsed = time.Since(s.start)
+	return st
+}
+
+// merge adds the stats of a helper searcher to those of the search.
+func (s *searcher[G]) merge(h *searcher[G]) {
+	st := h.stats
+	st.Nodes += h.nodes
+	s.stats.add(st)
+}
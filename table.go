@@ -0,0 +1,152 @@
+package vulpes
+
+import (
+	"sync"
+)
+
+// DefaultTableSize is the number of entries the transposition table used by SolveGame may grow to.
+const DefaultTableSize = 1 << 18
+
+// Hasher is an optional interface a Game may implement to allow the search to reuse the results of transposed positions.
+type Hasher interface {
+	// Hash returns a key identifying the game state. Two states with the same key must be interchangeable, so it should be unique for every distinct state.
+	Hash() uint64
+}
+
+type bound uint8
+
+const (
+	// emptyBound marks an unused table entry
+	emptyBound bound = iota
+	exactBound
+	lowerBound
+	upperBound
+)
+
+type tableEntry struct {
+	hash  uint64
+	score float64
+	depth uint32
+	best  int32
+	bound bound
+}
+
+// tableStripes is the number of locks guarding the entries of a shared table.
+const tableStripes = 1 << 10
+
+// initialTableSize is the number of entries a table which isn't shared starts with, before growing as it fills.
+const initialTableSize = 1 << 4
+
+// Table is a transposition table of bounded size, storing the results of previously searched positions.
+type Table struct {
+	entries []tableEntry
+	// locks guards the entries of a shared table, each lock guarding every tableStripes'th entry.
+	locks []sync.Mutex
+	// size is the number of entries the table may grow to.
+	size int
+	// used is the number of occupied entries, counted until the table has grown to its size.
+	used  int
+	shift uint
+}
+
+// NewTable returns a transposition table holding up to size entries. The size is rounded down to a power of 2. Memory is only allocated once the table is first used, and the table starts small, doubling as it fills until it reaches its size, so that short searches don't pay for a large table.
+func NewTable(size int) *Table {
+	t := &Table{size: 1}
+	for size > 1 {
+		size >>= 1
+		t.size <<= 1
+	}
+	return t
+}
+
+// NewSharedTable is like NewTable, but returns a table that is safe for concurrent use by multiple searches. Its memory is allocated up front.
+func NewSharedTable(size int) *Table {
+	t := NewTable(size)
+	t.allocate(t.size)
+	t.locks = make([]sync.Mutex, tableStripes)
+	return t
+}
+
+// Clear removes all entries from the table. It must not be called while the table is in use.
+func (t *Table) Clear() {
+	for i := range t.entries {
+		t.entries[i] = tableEntry{}
+	}
+	t.used = 0
+}
+
+// allocate replaces the entries of the table with n empty ones, n being a power of 2.
+func (t *Table) allocate(n int) {
+	t.entries = make([]tableEntry, n)
+	t.shift = 64
+	for n > 1 {
+		n >>= 1
+		t.shift--
+	}
+}
+
+// grow doubles the number of entries in a table which isn't shared, moving the existing entries to their new places.
+func (t *Table) grow() {
+	entries := t.entries
+	t.allocate(2 * len(entries))
+	t.used = 0
+	for _, entry := range entries {
+		if entry.bound != emptyBound {
+			t.put(entry)
+		}
+	}
+}
+
+func (t *Table) index(hash uint64) uint64 {
+	// Fibonacci hashing, to spread out keys with structured bits
+	return (hash * 0x9E3779B97F4A7C15) >> t.shift
+}
+
+func (t *Table) probe(hash uint64) (tableEntry, bool) {
+	if t.entries == nil {
+		return tableEntry{}, false
+	}
+	i := t.index(hash)
+	var entry tableEntry
+	if t.locks != nil {
+		lock := &t.locks[i%tableStripes]
+		lock.Lock()
+		entry = t.entries[i]
+		lock.Unlock()
+	} else {
+		entry = t.entries[i]
+	}
+	return entry, entry.bound != emptyBound && entry.hash == hash
+}
+
+func (t *Table) store(entry tableEntry) {
+	if t.locks != nil {
+		i := t.index(entry.hash)
+		lock := &t.locks[i%tableStripes]
+		lock.Lock()
+		t.entries[i] = entry
+		lock.Unlock()
+		return
+	}
+	if t.entries == nil {
+		n := initialTableSize
+		if n > t.size {
+			n = t.size
+		}
+		t.allocate(n)
+	}
+	t.put(entry)
+	if len(t.entries) < t.size && 4*t.used > len(t.entries) {
+		// Grow while collisions are rare, as each one overwrites an entry the search may still need
+		t.grow()
+	}
+}
+
+// put stores an entry in a table which isn't shared, counting the entries it occupies.
+func (t *Table) put(entry tableEntry) {
+	i := t.index(entry.hash)
+	if t.entries[i].bound == emptyBound {
+		t.used++
+	}
+	t.entries[i] = entry
+}
@@ -0,0 +1,208 @@
+package vulpes
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+)
+
+// tablebaseMagic identifies the format of a tablebase written by WriteTo.
+const tablebaseMagic = "VTB1"
+
+// ErrNotHasher is returned when generating a tablebase for a state which doesn't implement Hasher.
+var ErrNotHasher = errors.New("vulpes: state doesn't implement Hasher")
+
+// ErrTablebaseTooLarge is returned when a tablebase would hold more positions than allowed.
+var ErrTablebaseTooLarge = errors.New("vulpes: too many positions for the tablebase")
+
+// ErrInvalidTablebase is returned when reading a tablebase that wasn't written by WriteTo.
+var ErrInvalidTablebase = errors.New("vulpes: invalid tablebase")
+
+// Tablebase holds the exact ending, and the number of plies until it, of every position reachable from some starting state, keyed by their Hash.
+type Tablebase struct {
+	// keys holds the hash of every position, in increasing order.
+	keys []uint64
+	// values holds the ending of each position in its low 2 bits, and the plies until it in the rest.
+	values []uint32
+}
+
+// retrogradeNode is a position being solved by GenerateTablebase.
+type retrogradeNode struct {
+	hash uint64
+	// parents holds the index of every position with this one as a child, once for each time it's a child.
+	parents []int32
+	// remaining is the number of children yet to be solved.
+	remaining int
+	ending    int
+	plies     int
+	// losing is the most plies until a loss through any of the children solved so far, and tying the fewest until a tie, or -1 if there are none.
+	losing, tying int
+}
+
+// GenerateTablebase enumerates every position reachable from state, and solves them by retrograde analysis: starting from the positions where the game is over, the outcome of each position is worked out from those of its children, so that wins are found by the quickest route, and losses by the slowest. The state must implement Hasher, with a distinct Hash for every position.
+// Positions that neither player can force to an end, by repeating them forever, are recorded as ties 0 plies from the end. Chance nodes aren't supported.
+// It returns ErrTablebaseTooLarge if more than maxPositions positions are reachable, if maxPositions is positive.
+func GenerateTablebase(state Game, maxPositions int) (*Tablebase, error) {
+	if _, ok := state.(Hasher); !ok {
+		return nil, ErrNotHasher
+	}
+	// Enumerate the positions, breadth first
+	index := map[uint64]int32{state.(Hasher).Hash(): 0}
+	nodes := []retrogradeNode{{hash: state.(Hasher).Hash()}}
+	var queue []int32
+	for states := []Game{state}; len(states) > 0; {
+		var next []Game
+		for _, s := range states {
+			i := index[s.(Hasher).Hash()]
+			nodes[i].ending, _ = s.Evaluate()
+			nodes[i].losing, nodes[i].tying = -1, -1
+			if nodes[i].ending != UNFINISHED {
+				queue = append(queue, i)
+				continue
+			}
+			for _, child := range s.Children() {
+				hash := child.(Hasher).Hash()
+				j, seen := index[hash]
+				if !seen {
+					if maxPositions > 0 && len(nodes) >= maxPositions {
+						return nil, ErrTablebaseTooLarge
+					}
+					j = int32(len(nodes))
+					index[hash] = j
+					nodes = append(nodes, retrogradeNode{hash: hash})
+					next = append(next, child)
+				}
+				nodes[j].parents = append(nodes[j].parents, i)
+				nodes[i].remaining++
+			}
+		}
+		states = next
+	}
+	index = nil
+	// Solve the positions, working back from the ends of the game in order of the plies until them
+	for len(queue) > 0 {
+		child := &nodes[queue[0]]
+		queue = queue[1:]
+		for _, i := range child.parents {
+			n := &nodes[i]
+			if n.ending != UNFINISHED {
+				continue
+			}
+			n.remaining--
+			switch child.ending {
+			case LOSS:
+				// The quickest win is found first
+				n.ending, n.plies = WIN, child.plies+1
+				queue = append(queue, i)
+				continue
+			case TIE:
+				if n.tying < 0 || child.plies < n.tying {
+					n.tying = child.plies
+				}
+			case WIN:
+				n.losing = child.plies
+			}
+			if n.remaining == 0 {
+				if n.tying >= 0 {
+					n.ending, n.plies = TIE, n.tying+1
+				} else {
+					n.ending, n.plies = LOSS, n.losing+1
+				}
+				queue = append(queue, i)
+			}
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].hash < nodes[j].hash })
+	tb := &Tablebase{keys: make([]uint64, len(nodes)), values: make([]uint32, len(nodes))}
+	for i, n := range nodes {
+		if n.ending == UNFINISHED {
+			n.ending, n.plies = TIE, 0
+		}
+		tb.keys[i] = n.hash
+		tb.values[i] = uint32(n.plies)<<2 | uint32(n.ending)
+	}
+	return tb, nil
+}
+
+// Len returns the number of positions in the tablebase.
+func (tb *Tablebase) Len() int {
+	return len(tb.keys)
+}
+
+// Probe looks up a state in the tablebase, returning its ending with perfect play, from the perspective of the current player, and the number of plies until it. It returns false if the state isn't in the tablebase.
+func (tb *Tablebase) Probe(state Game) (ending int, plies int, ok bool) {
+	h, ok := state.(Hasher)
+	if !ok {
+		return UNFINISHED, 0, false
+	}
+	return tb.probe(h.Hash())
+}
+
+func (tb *Tablebase) probe(hash uint64) (ending int, plies int, ok bool) {
+	i := sort.Search(len(tb.keys), func(i int) bool { return tb.keys[i] >= hash })
+	if i == len(tb.keys) || tb.keys[i] != hash {
+		return UNFINISHED, 0, false
+	}
+	return int(tb.values[i] & 3), int(tb.values[i] >> 2), true
+}
+
+// WriteTo writes the tablebase to w, in a compact binary format which ReadTablebase reads back.
+func (tb *Tablebase) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var n int64
+	buf := make([]byte, 12)
+	copy(buf, tablebaseMagic)
+	binary.LittleEndian.PutUint64(buf[4:], uint64(len(tb.keys)))
+	for i := 0; ; i++ {
+		m, err := bw.Write(buf)
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+		if i == len(tb.keys) {
+			break
+		}
+		binary.LittleEndian.PutUint64(buf, tb.keys[i])
+		binary.LittleEndian.PutUint32(buf[8:], tb.values[i])
+	}
+	return n, bw.Flush()
+}
+
+// ReadTablebase reads a tablebase written by WriteTo.
+func ReadTablebase(r io.Reader) (*Tablebase, error) {
+	br := bufio.NewReader(r)
+	buf := make([]byte, 12)
+	if _, err := io.ReadFull(br, buf); err != nil {
+		return nil, err
+	}
+	if string(buf[:4]) != tablebaseMagic {
+		return nil, ErrInvalidTablebase
+	}
+	count := binary.LittleEndian.Uint64(buf[4:])
+	tb := &Tablebase{}
+	for i := uint64(0); i < count; i++ {
+		if _, err := io.ReadFull(br, buf); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		key := binary.LittleEndian.Uint64(buf)
+		if i > 0 && key <= tb.keys[i-1] {
+			return nil, ErrInvalidTablebase
+		}
+		tb.keys = append(tb.keys, key)
+		tb.values = append(tb.values, binary.LittleEndian.Uint32(buf[8:]))
+	}
+	return tb, nil
+}
+
+// tablebaseScore returns the score of a position found in the tablebase, ply plies from the root of the search.
+func tablebaseScore(ending int, plies int, ply int) float64 {
+	if ending == TIE {
+		return 0
+	}
+	return endingScore(ending, ply+plies)
+}
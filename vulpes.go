@@ -1,8 +1,9 @@
 package vulpes
 
 import (
+	"context"
 	"math"
-	"sort"
+	"time"
 )
 
 const (
@@ -24,71 +25,91 @@ type Game interface {
 	Evaluate() (ending int, heuristic float64)
 }
 
-type moveScore struct {
-	moveIndex int
-	moveScore float64
+// GameOf is the generic form of Game, for a game whose states are all of the concrete type G, such as a pointer to a struct. Searching a GameOf[G] with a SearcherOf[G], or SolveOf, avoids boxing every state in an interface, and returns results holding states of type G, with no need for type assertions. Game is itself a GameOf[Game], which is how the functions taking a Game search it.
+// The optional interfaces a Game may implement have generic forms too, such as TacticalOf[G]; those which don't mention Game, like Hasher and Mover, apply as they are.
+type GameOf[G any] interface {
+	// Children returns the child nodes from this one. If the game is not ended, this must return at least 1 child.
+	Children() []G
+	// Evaluate returns an evaluation of the current game state from the perspective of the current player, as for Game.
+	Evaluate() (ending int, heuristic float64)
 }
 
-type moveScores []moveScore
-
-func (s moveScores) Len() int           { return len(s) }
-func (s moveScores) Less(i, j int) bool { return s[i].moveScore > s[j].moveScore }
-func (s moveScores) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
-
 // Search returns the computed score of a given state.
 func Search(state Game, depth uint, alpha, beta float64) (Game, float64) {
-	ending, heuristic := state.Evaluate()
-	switch ending {
-	case LOSS:
-		return state, math.Inf(-1)
-	case TIE:
-		return state, 0
-	case WIN:
-		return state, math.Inf(1)
-	}
-	if depth == 0 {
-		return state, heuristic
-	}
-	children := state.Children()
-	moveScores := make(moveScores, len(children))
-	for i := range children {
-		moveScores[i] = moveScore{i, 0.0}
-	}
-	var tmpScore float64
-	if depth > 1 {
-		// Pre-sort the possible moves by their score to speed up the pruning
-		for i, child := range children {
-			// Depth-0 search to force heuristic scoring
-			_, tmpScore = Search(child, 0, -beta, -alpha)
-			moveScores[i].moveScore = -tmpScore
-		}
-		sort.Sort(moveScores)
+	return newSearcher[Game](nil).search(state, depth, alpha, beta, 0)
+}
+
+// SearchTable is like Search, but reuses results for transposed positions through the given table when the state implements Hasher. The table may be nil.
+func SearchTable(state Game, depth uint, alpha, beta float64, table *Table) (Game, float64) {
+	return newSearcher[Game](table).search(state, depth, alpha, beta, 0)
+}
+
+// SearchPVS is like Search, but uses Principal Variation Search (NegaScout): every child after the first is searched with a null window, to prove that it's no better than the best so far, and is only searched again with the full window if that fails. This is faster than Search when the children are well ordered, and produces the same score.
+func SearchPVS(state Game, depth uint, alpha, beta float64) (Game, float64) {
+	s := newSearcher[Game](nil)
+	s.pvs = true
+	return s.search(state, depth, alpha, beta, 0)
+}
+
+// newTable returns a table of up to DefaultTableSize entries if the state implements Hasher, and nil otherwise.
+func newTable(state any) *Table {
+	if _, ok := state.(Hasher); ok {
+		return NewTable(DefaultTableSize)
 	}
-	var bestChild Game
-	for _, moveScore := range moveScores {
-		child := children[moveScore.moveIndex]
-		_, tmpScore = Search(child, depth-1, -beta, -alpha)
-		tmpScore = -tmpScore
-		if tmpScore > alpha {
-			alpha = tmpScore
-			bestChild = child
-			if beta <= alpha {
-				return bestChild, beta
-			}
-		}
-		if bestChild == nil {
-			// Take the first child, in case all the children are terrible.
-			bestChild = child
-		}
+	return nil
+}
+
+// SearchContext is like Search, but gives up once ctx is done, returning the best child found so far (out of those completely searched, or else the first to be searched), and ctx.Err().
+func SearchContext(ctx context.Context, state Game, depth uint, alpha, beta float64) (Game, float64, error) {
+	s := newSearcher[Game](nil)
+	s.ctx, s.stoppable = ctx, true
+	best, score := s.search(state, depth, alpha, beta, 0)
+	if s.stopped {
+		return best, score, ctx.Err()
 	}
-	if bestChild == nil {
-		// No possible moves, so return the current state.
-		bestChild = state
+	return best, score, nil
+}
+
+// newSharedTable is like newTable, but returns a table that is safe for concurrent use.
+func newSharedTable(state any) *Table {
+	if _, ok := state.(Hasher); ok {
+		return NewSharedTable(DefaultTableSize)
 	}
-	return bestChild, alpha
+	return nil
 }
 
-// SolveGame takes a starting node for the game, and returns the best child node and its score, after searching to the specified depth
+// SolveGame takes a starting node for the game, and returns the best child node and its score, after searching to the specified depth.
+// If the state implements Hasher, a transposition table of DefaultTableSize entries is used.
 func SolveGame(state Game, depth uint) (Game, float64) {
-	return Search(state, depth, math.Inf(-1), math.Inf(1))
+	result := Solve(state, depth)
+	return result.Best, result.Score
+}
+
+// SolveGameTable is like SolveGame, but uses the given transposition table, which may be nil, or shared between calls.
+func SolveGameTable(state Game, depth uint, table *Table) (Game, float64) {
+	return SearchTable(state, depth, math.Inf(-1), math.Inf(1), table)
+}
+
+// SolveGamePVS is like SolveGame, but uses Principal Variation Search, as in SearchPVS.
+func SolveGamePVS(state Game, depth uint) (Game, float64) {
+	result, _ := NewSearcher(Options{Depth: depth, Algorithm: PVS}).Search(context.Background(), state)
+	return result.Best, result.Score
+}
+
+// SolveGameContext is like SolveGame, but gives up once ctx is done, returning the best child found so far (out of those completely searched, or else the first to be searched), and ctx.Err().
+func SolveGameContext(ctx context.Context, state Game, depth uint) (Game, float64, error) {
+	result, err := SolveContext(ctx, state, depth)
+	return result.Best, result.Score, err
+}
+
+// SolveGameTimed takes a starting node for the game, and returns the best child node and its score, after searching as deep as possible within the given time limit.
+func SolveGameTimed(state Game, limit time.Duration) (Game, float64) {
+	result := SolveTimed(state, limit)
+	return result.Best, result.Score
+}
+
+// SolveGameDeadline is like SolveGameTimed, but searches until the given deadline.
+func SolveGameDeadline(state Game, deadline time.Time) (Game, float64) {
+	result := SolveDeadline(state, deadline)
+	return result.Best, result.Score
 }